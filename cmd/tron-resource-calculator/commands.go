@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// command is a subcommand of the CLI. The values are bits, so a flag or a
+// usage line can belong to several commands.
+type command int
+
+const (
+	cmdMonitor command = 1 << iota
+	cmdOnce
+	cmdSimulate
+	cmdCompare
+	cmdAnalyze
+
+	// cmdFlat is the deprecated invocation without a command, it accepts
+	// the flags of every command
+	cmdFlat
+)
+
+// Commands that share flags
+const (
+	cmdLive     = cmdMonitor | cmdOnce                            // poll a node
+	cmdAnalysis = cmdMonitor | cmdSimulate | cmdAnalyze           // analyse snapshots
+	cmdReports  = cmdMonitor | cmdOnce | cmdSimulate | cmdAnalyze // write reports
+	cmdAll      = cmdReports | cmdCompare
+)
+
+var commands = []struct {
+	cmd     command
+	name    string
+	summary string
+}{
+	{cmdMonitor, "monitor", "Monitor accounts, analyse regeneration and consumption and save a report"},
+	{cmdOnce, "once", "Print a single snapshot of each account and exit"},
+	{cmdSimulate, "simulate", "Simulate transactions against a saved JSON report, without polling a node"},
+	{cmdAnalyze, "analyze", "Analyze saved snapshots from scratch, also files of other tools, without polling a node"},
+	{cmdCompare, "compare", "Compare saved JSON reports"},
+}
+
+func (c command) String() string {
+	for _, cmd := range commands {
+		if cmd.cmd == c {
+			return cmd.name
+		}
+	}
+	return ""
+}
+
+// parseCommand splits the command name off args. Arguments starting with a
+// dash are the flat invocation of older versions; ok is false when args
+// name no command at all.
+func parseCommand(args []string) (cmd command, rest []string, ok bool) {
+	if len(args) == 0 {
+		return 0, nil, false
+	}
+	for _, c := range commands {
+		if args[0] == c.name {
+			return c.cmd, args[1:], true
+		}
+	}
+	if strings.HasPrefix(args[0], "-") && !isHelp(args[0]) {
+		return cmdFlat, args, true
+	}
+	return 0, nil, false
+}
+
+func isHelp(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help", "help":
+		return true
+	}
+	return false
+}
+
+// printCommands prints the list of commands
+func printCommands() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Monitor TRON account Energy and Bandwidth resources in real-time.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> --help' for the options of a command.\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Flags without a command (%s -a TXxx ...) still monitor, but that is deprecated.\n", os.Args[0])
+}
+
+// usageLine is a line of the --help text and the commands it applies to.
+// A value may span several lines.
+type usageLine struct {
+	commands command
+	text     string
+}
+
+type usageSection struct {
+	title string
+	lines []usageLine
+}
+
+// printUsage prints the options of cmd, grouped like the README
+func printUsage(cmd command) {
+	switch cmd {
+	case cmdSimulate:
+		fmt.Fprintf(os.Stderr, "Usage: %s simulate [options] <report.json>\n\n", os.Args[0])
+	case cmdAnalyze:
+		fmt.Fprintf(os.Stderr, "Usage: %s analyze [options] <snapshots.json>\n\n", os.Args[0])
+	case cmdCompare:
+		fmt.Fprintf(os.Stderr, "Usage: %s compare [options] <report.json|glob|directory>...\n\n", os.Args[0])
+	case cmdFlat:
+		fmt.Fprintf(os.Stderr, "Usage (deprecated): %s --address <TRON_ADDRESS> [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Use '%s monitor', 'once', 'simulate' or 'compare' instead.\n\n", os.Args[0])
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: %s %s --address <TRON_ADDRESS> [options]\n\n", os.Args[0], cmd)
+	}
+	for _, c := range commands {
+		if c.cmd == cmd {
+			fmt.Fprintf(os.Stderr, "%s.\n\n", c.summary)
+		}
+	}
+
+	first := true
+	for _, section := range usageSections() {
+		var lines []string
+		for _, line := range section.lines {
+			// The flat invocation monitors, plus its own --once and --replay
+			if line.commands&cmd != 0 || (cmd == cmdFlat && line.commands&cmdMonitor != 0) {
+				lines = append(lines, line.text)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(os.Stderr)
+		}
+		first = false
+		fmt.Fprintf(os.Stderr, "%s:\n", section.title)
+		for _, line := range lines {
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
+
+func usageSections() []usageSection {
+	prog := os.Args[0]
+	return []usageSection{
+		{"Basic Flags", []usageLine{
+			{cmdLive, "  -a, --address      TRON wallet address (required, format: T... or 41...)\n" +
+				"                     repeat or comma-separate to monitor several addresses"},
+			{cmdSimulate | cmdCompare, "  -a, --address      Only use reports of this address"},
+			{cmdAnalyze, "  -a, --address      Address of the snapshots, needed when the file has no metadata"},
+			{cmdLive, "  -n, --node         TRON node URL (default: URL of --network)\n" +
+				"                     repeat or comma-separate to add fallback nodes"},
+			{cmdLive, fmt.Sprintf("      --network      Network preset: %s (default: %s)", strings.Join(tronres.Networks(), ", "), defaultNetwork)},
+			{cmdLive, fmt.Sprintf("      --api-key      TronGrid API key, falls back to $%s\n", apiKeyEnv) +
+				"                     (not needed for self-hosted nodes)"},
+			{cmdMonitor, fmt.Sprintf("  -d, --duration     Monitoring duration in seconds (default: %d)", defaultDuration)},
+			{cmdMonitor, fmt.Sprintf("  -i, --interval     Sampling interval in ms (default: %d)", defaultInterval)},
+		}},
+		{"Connection Flags", []usageLine{
+			{cmdLive, "      --timeout      HTTP request timeout (default: 5s, 10s with API key)"},
+			{cmdLive, fmt.Sprintf("      --retries      Attempts per request (default: %d)", defaultRetries)},
+			{cmdLive, fmt.Sprintf("      --backoff      Initial retry backoff, doubled per attempt up to 5s (default: %s)", defaultBackoff)},
+			{cmdLive, "      --proxy        Proxy URL, e.g. http://proxy:3128 or socks5://127.0.0.1:1080\n" +
+				"                     (default: $HTTP_PROXY / $HTTPS_PROXY, honoring $NO_PROXY)"},
+			{cmdLive, "      --max-rps      Max requests per second to the nodes for all addresses, retries included"},
+			{cmdLive, "      --solidity     Read confirmed resources from /walletsolidity (lags about a minute)"},
+		}},
+		{"Advanced Flags", []usageLine{
+			{cmdMonitor, "      --until-full   Monitor until resources are fully recovered"},
+			{cmdFlat, "      --once         Print a single snapshot and exit (saved only with an explicit --format json)"},
+			{cmdMonitor, fmt.Sprintf("      --max-duration Max duration for --until-full (default: %d)", defaultMaxDuration)},
+			{cmdMonitor, "      --recovery-threshold  Percent of the limit still used that counts as recovered (default: 0)"},
+			{cmdMonitor, "      --recovery-target     Resource --until-full waits for: both, energy or bandwidth (default: both)"},
+			{cmdAnalysis, "      --compare      Compare with previous log files, a glob or a directory\n" +
+				"                     (several runs are shown as a table sorted by start time)"},
+			{cmdAnalysis, "      --filter-outliers  Reject implausible regeneration spikes from the analysis"},
+			{cmdAnalysis, "      --window       Regeneration rates per window of this length, e.g. 10m"},
+			{cmdMonitor, "      --resume       Continue a previous JSON log file, -a defaults to its address"},
+			{cmdFlat, "      --replay       Analyze a previous JSON log file again without contacting a node\n" +
+				"                     (a new report is saved only with --out-file or --out-dir)"},
+			{cmdMonitor, "      --metrics-addr Serve Prometheus metrics at http://<addr>/metrics (e.g. :9100)"},
+			{cmdMonitor | cmdSimulate | cmdAnalyze, fmt.Sprintf("      --format       Output format: json, csv, both, md, html or influx (default: %s)", defaultFormat)},
+			{cmdOnce, "      --format       Save the snapshot as a JSON report with --format json"},
+			{cmdReports, "      --out-dir      Directory for report files (created if missing)"},
+			{cmdReports, "      --out-file     Report file name; an absolute path is used as-is for the main output"},
+			{cmdSimulate | cmdAnalyze, "                     (a report is saved only with --out-file or --out-dir)"},
+			{cmdAnalysis, "      --graph        Print sparklines of energy and bandwidth availability after the summary"},
+			{cmdReports, "      --quiet        Don't print the header and snapshot lines, only the summary"},
+			{cmdReports, "      --json-stdout  Write the JSON report to stdout and all other output to stderr"},
+			{cmdAll, "      --timezone     Time zone of console timestamps: utc, local or an IANA name such as\n" +
+				"                     Europe/Berlin (default: utc); reports keep RFC 3339 with offset"},
+			{cmdReports, "      --timezone-filenames  Also use --timezone for the timestamp in generated file names"},
+			{cmdAll, fmt.Sprintf("      --number-format  Thousands separator: %s (default: %s)", strings.Join(output.NumberFormats(), ", "), output.NumberComma)},
+			{cmdMonitor, "      --stream       Write snapshots to .ndjson as they arrive, analysis to .analysis.json"},
+		}},
+		{"Webhook Flags (JSON POST, delivery failures are only warnings)", []usageLine{
+			{cmdMonitor, "      --webhook      URL notified when the resources fully recover"},
+			{cmdMonitor, "      --webhook-energy     Also notify when available energy rises to this"},
+			{cmdMonitor, "      --webhook-bandwidth  Also notify when available bandwidth rises to this"},
+		}},
+		{"InfluxDB Flags (line protocol, one point per snapshot)", []usageLine{
+			{cmdMonitor, "      --influx-url   POST to this write URL after monitoring, e.g.\n" +
+				"                     http://localhost:8086/api/v2/write?org=o&bucket=b&precision=ns"},
+			{cmdMonitor, fmt.Sprintf("      --influx-token API token, falls back to $%s", influxTokenEnv)},
+			{cmdAnalysis, fmt.Sprintf("      --influx-measurement  Measurement name (default: %s)", output.DefaultInfluxMeasurement)},
+			{cmdAnalysis, "      --influx-address-tag  Tag key for the address (default: address)"},
+			{cmdAnalysis, "      --influx-node-tag     Tag key for the node (default: node)"},
+		}},
+		{"Simulation Flags", []usageLine{
+			{cmdMonitor, "      --simulate     Run transaction simulation after monitoring"},
+			{cmdAnalyze, "      --simulate     Run transaction simulation after the analysis"},
+			{cmdAnalysis, "      --tx-cost      Energy cost per transaction (default: 65000, 0 for energy-free)\n" +
+				"                     or a weighted mix cost:weight,... e.g. 0:0.2,65000:0.5,131000:0.3"},
+			{cmdAnalysis, "      --bw-cost      Bandwidth cost per transaction, e.g. 268 for a TRX transfer"},
+			{cmdAnalysis, "      --target-tx    Target transactions per day (default: 800)"},
+			{cmdAnalysis, "      --forecast-tx  Print when energy for N transactions at --tx-cost is available"},
+			{cmdMonitor, "      --energy-fee   Energy price in sun for TRX burn estimates (default: query node)"},
+			{cmdSimulate | cmdAnalyze, "      --energy-fee   Energy price in sun for TRX burn estimates (no estimates without it)"},
+		}},
+		{"Alert Flags (exit code 2 when not met, after the report is saved)", []usageLine{
+			{cmdAnalysis, "      --min-tx-per-day  Minimum sustained tx/day at --tx-cost / --bw-cost"},
+			{cmdAnalysis, "      --min-energy      Minimum energy available at the end of monitoring"},
+		}},
+		{"Config File", []usageLine{
+			{cmdAll, "      --config       Read flags from a YAML file, keys are long flag names:\n" +
+				"                       node: https://api.trongrid.io\n" +
+				"                       address: [TXxx, TYyy]\n" +
+				"                       interval: 3000\n" +
+				"                     precedence: defaults < config file < command-line flags\n" +
+				fmt.Sprintf("                     ($%s is used only when no API key is set either way)\n", apiKeyEnv) +
+				"                     keys of flags the command doesn't have are ignored"},
+		}},
+		{"Examples", []usageLine{
+			{cmdMonitor, fmt.Sprintf("  %s monitor -a TXxx -d 60", prog)},
+			{cmdMonitor, fmt.Sprintf("  %s monitor -a TXxx --network nile", prog)},
+			{cmdMonitor, fmt.Sprintf("  %s monitor -a TXxx --duration 3600 --interval 3000", prog)},
+			{cmdMonitor, fmt.Sprintf("  %s monitor -a TXxx --until-full --max-duration 86400", prog)},
+			{cmdMonitor, fmt.Sprintf("  %s monitor -a TXxx --simulate --tx-cost 65000 --target-tx 800", prog)},
+			{cmdMonitor, fmt.Sprintf("  %s monitor --config monitor.yaml -d 600", prog)},
+			{cmdOnce, fmt.Sprintf("  %s once -a TXxx", prog)},
+			{cmdOnce, fmt.Sprintf("  %s once -a TXxx --json-stdout | jq .snapshots[0].energy_available", prog)},
+			{cmdSimulate, fmt.Sprintf("  %s simulate --tx-cost 65000 --target-tx 800 report.json", prog)},
+			{cmdSimulate, fmt.Sprintf("  %s simulate --tx-cost 0 --bw-cost 268 --target-tx 200 report.json", prog)},
+			{cmdSimulate, fmt.Sprintf("  %s simulate --tx-cost 65000:0.7,131000:0.3 --energy-fee 210 report.json", prog)},
+			{cmdAnalyze, fmt.Sprintf("  %s analyze report.json", prog)},
+			{cmdAnalyze, fmt.Sprintf("  %s analyze -a TXxx --simulate --window 10m snapshots.json", prog)},
+			{cmdCompare, fmt.Sprintf("  %s compare old.json new.json", prog)},
+			{cmdCompare, fmt.Sprintf("  %s compare -a TXxx ./logs", prog)},
+			{cmdFlat, fmt.Sprintf("  %s -a TXxx -d 60  (same as: %s monitor -a TXxx -d 60)", prog, prog)},
+		}},
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// compareWithPrevious compares the current analysis with the reports named by
+// patterns. A single report is diffed against the current run, several are
+// shown as a trend table. Files listed in exclude (the reports this run just
+// wrote) are ignored, as are files that fail to load, with a warning.
+func compareWithPrevious(patterns []string, address string, current tronres.Analysis, exclude []string) error {
+	names, reports, err := loadCompareReports(patterns, exclude)
+	if err != nil {
+		return err
+	}
+
+	// A single file is compared as before, whatever address it was recorded for
+	if len(reports) == 1 {
+		output.PrintComparison(names[0], reports[0].Analysis, current)
+		return nil
+	}
+
+	// A folder of logs may hold several addresses, keep the ones of this run
+	reports = slices.DeleteFunc(reports, func(r tronres.MonitorReport) bool {
+		return !sameAddress(r.Metadata.Address, address)
+	})
+	if len(reports) == 0 {
+		return fmt.Errorf("no reports of %s found in %s", address, strings.Join(patterns, ", "))
+	}
+	slices.SortStableFunc(reports, func(a, b tronres.MonitorReport) int {
+		return a.Metadata.StartTime.Compare(b.Metadata.StartTime)
+	})
+
+	runs := make([]output.TrendRun, 0, len(reports)+1)
+	for _, report := range reports {
+		runs = append(runs, output.TrendRun{Start: report.Metadata.StartTime, Analysis: report.Analysis})
+	}
+	runs = append(runs, output.TrendRun{Analysis: current})
+	output.PrintTrend(runs)
+	return nil
+}
+
+// compareReports compares saved reports without a current run, for the
+// compare command. Two reports are diffed, older against newer, more are
+// shown as a trend table. Reports of other addresses than cfg.Addresses are
+// ignored; without -a all reports must be of the same address.
+func compareReports(cfg models.Config, patterns []string) error {
+	names, reports, err := loadCompareReports(patterns, nil)
+	if err != nil {
+		return err
+	}
+
+	type run struct {
+		name   string
+		report tronres.MonitorReport
+	}
+	var runs []run
+	var addresses []string
+	for i, report := range reports {
+		address := report.Metadata.Address
+		if len(cfg.Addresses) > 0 && !slices.ContainsFunc(cfg.Addresses, func(a string) bool { return sameAddress(a, address) }) {
+			continue
+		}
+		if !slices.ContainsFunc(addresses, func(a string) bool { return sameAddress(a, address) }) {
+			addresses = append(addresses, address)
+		}
+		runs = append(runs, run{name: names[i], report: report})
+	}
+
+	if len(addresses) > 1 {
+		return fmt.Errorf("the reports are of %d addresses (%s), pick one with -a", len(addresses), strings.Join(addresses, ", "))
+	}
+	if len(runs) < 2 {
+		return fmt.Errorf("compare needs at least two reports, found %d in %s", len(runs), strings.Join(patterns, ", "))
+	}
+	slices.SortStableFunc(runs, func(a, b run) int {
+		return a.report.Metadata.StartTime.Compare(b.report.Metadata.StartTime)
+	})
+
+	if len(runs) == 2 {
+		output.PrintComparison(runs[0].name, runs[0].report.Analysis, runs[1].report.Analysis)
+		return nil
+	}
+
+	trend := make([]output.TrendRun, 0, len(runs))
+	for _, r := range runs {
+		trend = append(trend, output.TrendRun{Start: r.report.Metadata.StartTime, Analysis: r.report.Analysis})
+	}
+	output.PrintTrend(trend)
+	return nil
+}
+
+// loadCompareReports loads the reports named by patterns, skipping the files
+// in exclude and, with a warning, the files that fail to load
+func loadCompareReports(patterns []string, exclude []string) ([]string, []tronres.MonitorReport, error) {
+	files, err := resolveCompareFiles(patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	var reports []tronres.MonitorReport
+	for _, filename := range files {
+		if slices.ContainsFunc(exclude, func(e string) bool { return sameFile(e, filename) }) {
+			continue
+		}
+		report, err := loadReport(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", filename, err)
+			continue
+		}
+		names = append(names, filename)
+		reports = append(reports, report)
+	}
+	return names, reports, nil
+}
+
+// resolveCompareFiles expands --compare values into report files. A directory
+// stands for the JSON files in it, a value with glob characters for its matches.
+func resolveCompareFiles(patterns []string) ([]string, error) {
+	var files []string
+	add := func(name string) {
+		if !slices.ContainsFunc(files, func(f string) bool { return sameFile(f, name) }) {
+			files = append(files, name)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, "*.json")
+		} else if !strings.ContainsAny(pattern, "*?[") {
+			add(pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --compare pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: no files match %s\n", pattern)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	return files, nil
+}
+
+// sameFile reports whether a and b name the same path
+func sameFile(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
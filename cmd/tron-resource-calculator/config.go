@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// shorthands maps short flag names to the long names used in config files
+var shorthands = map[string]string{
+	"a": "address",
+	"n": "node",
+	"d": "duration",
+	"i": "interval",
+}
+
+// configEntry is one key of a config file with its value(s)
+type configEntry struct {
+	key    string
+	values []string
+	line   int
+}
+
+// applyConfigFile sets the flags listed in a YAML config file. Keys are long
+// flag names; flags given on the command line are left untouched, so the
+// precedence is defaults < config file < command-line flags. Keys that only
+// exist in other, the flags of the other commands, are skipped, so one file
+// can serve every command.
+func applyConfigFile(fs, other *flag.FlagSet, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	entries, err := parseConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		name := f.Name
+		if long, ok := shorthands[name]; ok {
+			name = long
+		}
+		explicit[name] = true
+	})
+
+	for _, e := range entries {
+		if long, ok := shorthands[e.key]; ok {
+			return fmt.Errorf("%s: line %d: use the long name %q instead of %q", filename, e.line, long, e.key)
+		}
+		if e.key == "config" || fs.Lookup(e.key) == nil && other.Lookup(e.key) == nil {
+			return fmt.Errorf("%s: line %d: unknown key %q (keys are long flag names, see --help)", filename, e.line, e.key)
+		}
+		if explicit[e.key] || fs.Lookup(e.key) == nil {
+			continue
+		}
+		for _, v := range e.values {
+			if err := fs.Set(e.key, v); err != nil {
+				return fmt.Errorf("%s: line %d: invalid value %q for %s: %w", filename, e.line, v, e.key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseConfig parses the YAML subset used by config files: top-level
+// "key: value" pairs, where a value is a scalar, an inline list [a, b]
+// or a block list of "- item" lines
+func parseConfig(data []byte) ([]configEntry, error) {
+	var entries []configEntry
+	seen := make(map[string]int)
+	var list *configEntry // entry collecting "- item" lines
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		raw := stripComment(scanner.Text())
+		line := strings.TrimSpace(raw)
+		if line == "" || line == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "- ") || line == "-" {
+			if list == nil {
+				return nil, fmt.Errorf("line %d: list item without a key", lineNo)
+			}
+			value, err := unquote(strings.TrimSpace(strings.TrimPrefix(line, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			list.values = append(list.values, value)
+			continue
+		}
+
+		if raw != strings.TrimLeft(raw, " \t") {
+			return nil, fmt.Errorf("line %d: nested keys are not supported", lineNo)
+		}
+		if list != nil {
+			if len(list.values) == 0 {
+				return nil, fmt.Errorf("line %d: key %q has no value", list.line, list.key)
+			}
+			entries = append(entries, *list)
+			list = nil
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		if prev, dup := seen[key]; dup {
+			return nil, fmt.Errorf("line %d: key %q already set on line %d", lineNo, key, prev)
+		}
+		seen[key] = lineNo
+
+		entry := configEntry{key: key, line: lineNo}
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "":
+			list = &entry
+			continue
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("line %d: unterminated list", lineNo)
+			}
+			for _, item := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"), ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				v, err := unquote(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				entry.values = append(entry.values, v)
+			}
+		default:
+			v, err := unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			entry.values = []string{v}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if list != nil {
+		if len(list.values) == 0 {
+			return nil, fmt.Errorf("line %d: key %q has no value", list.line, list.key)
+		}
+		entries = append(entries, *list)
+	}
+
+	return entries, nil
+}
+
+// stripComment removes a trailing "# comment" that is not inside quotes
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote strips matching single or double quotes around a scalar
+func unquote(value string) (string, error) {
+	if len(value) == 0 || (value[0] != '"' && value[0] != '\'') {
+		return value, nil
+	}
+	if len(value) < 2 || value[len(value)-1] != value[0] {
+		return "", fmt.Errorf("unterminated quoted value %s", value)
+	}
+	return value[1 : len(value)-1], nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// stringList is a flag that can be repeated or given as a comma-separated list.
+// The first explicit value replaces the default.
+type stringList struct {
+	values []string
+	set    bool
+}
+
+func newStringList(defaults ...string) *stringList {
+	return &stringList{values: defaults}
+}
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(l.values, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	if !l.set {
+		l.values = nil
+		l.set = true
+	}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l.values = append(l.values, v)
+		}
+	}
+	return nil
+}
+
+// parseTxCost parses --tx-cost: a single energy cost, or a weighted mix of
+// "cost:weight" pairs. For a mix the returned cost is the blended average.
+func parseTxCost(value string) (int64, []tronres.TxCostShare, error) {
+	if !strings.Contains(value, ":") {
+		cost, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid --tx-cost %q: expected a number or cost:weight pairs", value)
+		}
+		if cost < 0 {
+			return 0, nil, fmt.Errorf("--tx-cost must not be negative")
+		}
+		return cost, nil, nil
+	}
+
+	var mix []tronres.TxCostShare
+	for _, item := range strings.Split(value, ",") {
+		costStr, weightStr, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return 0, nil, fmt.Errorf("invalid --tx-cost entry %q: every entry of a mix needs a weight (cost:weight)", item)
+		}
+		cost, err := strconv.ParseInt(costStr, 10, 64)
+		if err != nil || cost < 0 {
+			return 0, nil, fmt.Errorf("invalid --tx-cost entry %q: cost must be a non-negative integer", item)
+		}
+		weight, err := strconv.ParseFloat(weightStr, 64)
+		if err != nil || weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return 0, nil, fmt.Errorf("invalid --tx-cost entry %q: weight must be a positive number", item)
+		}
+		mix = append(mix, tronres.TxCostShare{Cost: cost, Weight: weight})
+	}
+
+	return tronres.BlendedTxCost(mix), mix, nil
+}
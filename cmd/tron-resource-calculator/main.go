@@ -3,92 +3,232 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"math"
+	"net/url"
 	"os"
-	"os/signal"
-	"syscall"
+	"slices"
+	"strings"
 	"time"
+	_ "time/tzdata" // --timezone works where the system has no zoneinfo
 
-	"github.com/sxwebdev/tron-resource-calculator/internal/client"
 	"github.com/sxwebdev/tron-resource-calculator/internal/models"
-	"github.com/sxwebdev/tron-resource-calculator/internal/monitor"
 	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
 )
 
 const (
-	defaultNode        = "https://api.trongrid.io"
+	defaultNetwork     = "mainnet"
 	defaultDuration    = 20
 	defaultInterval    = 1000
 	defaultMaxDuration = 86400
+	defaultFormat      = formatJSON
+	defaultRetries     = 3
+	defaultBWFee       = 1000 // sun per bandwidth, used when chain parameters are unavailable
+	defaultBackoff     = 100 * time.Millisecond
+
+	// pricesTimeout bounds the chain parameters request made after monitoring
+	pricesTimeout = 3 * time.Second
+
+	// solidityMinDuration is the run length in seconds below which --solidity warns about the lag
+	solidityMinDuration = 300
+
+	apiKeyEnv      = "TRON_PRO_API_KEY"
+	influxTokenEnv = "INFLUX_TOKEN"
+)
+
+// Output formats accepted by --format
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatBoth   = "both"
+	formatMD     = "md"
+	formatInflux = "influx"
+	formatHTML   = "html"
 )
 
 func main() {
+	cmd, args, ok := parseCommand(os.Args[1:])
+	if !ok {
+		printCommands()
+		if len(os.Args) > 1 && isHelp(os.Args[1]) {
+			os.Exit(0)
+		}
+		if len(os.Args) > 1 {
+			fmt.Fprintf(os.Stderr, "\nError: unknown command %q\n", os.Args[1])
+		}
+		os.Exit(1)
+	}
+	if cmd == cmdFlat {
+		fmt.Fprintf(os.Stderr, "Warning: flags without a command are deprecated and will be removed, use '%s monitor' (or once, simulate, compare)\n", os.Args[0])
+	}
+
+	// Every command parses its own flags. The flags of the other commands
+	// go to a set that is never parsed, so they keep their defaults.
+	fs := flag.NewFlagSet(cmd.String(), flag.ExitOnError)
+	other := flag.NewFlagSet("", flag.ContinueOnError)
+	on := func(cmds command) *flag.FlagSet {
+		if cmd == cmdFlat || cmd&cmds != 0 {
+			return fs
+		}
+		return other
+	}
+
 	// Parse command line flags
-	address := flag.String("address", "", "TRON wallet address (required)")
-	addressShort := flag.String("a", "", "TRON wallet address (shorthand)")
-	node := flag.String("node", defaultNode, "TRON node URL")
-	nodeShort := flag.String("n", "", "TRON node URL (shorthand)")
-	duration := flag.Int("duration", defaultDuration, "Monitoring duration in seconds")
-	durationShort := flag.Int("d", 0, "Monitoring duration in seconds (shorthand)")
+	addresses := newStringList()
+	on(cmdAll).Var(addresses, "address", "TRON wallet address (required), repeat or comma-separate to monitor several")
+	on(cmdAll).Var(addresses, "a", "TRON wallet address (shorthand)")
+	nodes := newStringList()
+	on(cmdLive).Var(nodes, "node", "TRON node URL, repeat or comma-separate for fallbacks")
+	on(cmdLive).Var(nodes, "n", "TRON node URL (shorthand)")
+	network := on(cmdLive).String("network", defaultNetwork, "Network preset: mainnet, nile or shasta")
+	apiKey := on(cmdLive).String("api-key", "", "TronGrid API key (env: "+apiKeyEnv+")")
+	timeout := on(cmdLive).Duration("timeout", 0, "HTTP request timeout (default: 5s, 10s with API key)")
+	retries := on(cmdLive).Int("retries", defaultRetries, "Attempts per request")
+	backoff := on(cmdLive).Duration("backoff", defaultBackoff, "Initial retry backoff, doubled per attempt")
+	proxy := on(cmdLive).String("proxy", "", "HTTP, HTTPS or SOCKS5 proxy URL (default: HTTP_PROXY/HTTPS_PROXY from the environment)")
+	maxRPS := on(cmdLive).Float64("max-rps", 0, "Max requests per second to the nodes, retries included (0 = unlimited)")
+	solidity := on(cmdLive).Bool("solidity", false, "Read confirmed resources from /walletsolidity instead of the full node's latest state")
+	duration := on(cmdMonitor).Int("duration", defaultDuration, "Monitoring duration in seconds")
+	durationShort := on(cmdMonitor).Int("d", 0, "Monitoring duration in seconds (shorthand)")
 
 	// New flags
-	interval := flag.Int("interval", defaultInterval, "Sampling interval in milliseconds")
-	intervalShort := flag.Int("i", 0, "Sampling interval in ms (shorthand)")
-	untilFull := flag.Bool("until-full", false, "Monitor until resources are fully recovered")
-	maxDuration := flag.Int("max-duration", defaultMaxDuration, "Max duration when using --until-full (seconds)")
-	compareFile := flag.String("compare", "", "Compare with previous log file (JSON)")
+	interval := on(cmdMonitor).Int("interval", defaultInterval, "Sampling interval in milliseconds")
+	intervalShort := on(cmdMonitor).Int("i", 0, "Sampling interval in ms (shorthand)")
+	untilFull := on(cmdMonitor).Bool("until-full", false, "Monitor until resources are fully recovered")
+	onceFlag := on(cmdFlat).Bool("once", false, "Print a single snapshot and exit, without monitoring or analysis")
+	maxDuration := on(cmdMonitor).Int("max-duration", defaultMaxDuration, "Max duration when using --until-full (seconds)")
+	recoveryThreshold := on(cmdMonitor).Float64("recovery-threshold", 0, "With --until-full, stop once at most this percent of the limit is used (0 = fully unused)")
+	recoveryTarget := on(cmdMonitor).String("recovery-target", tronres.RecoverBoth, "With --until-full, the resource that has to recover: both, energy or bandwidth")
+	compareFiles := newStringList()
+	on(cmdAnalysis).Var(compareFiles, "compare", "Compare with previous log files (JSON), a glob or a directory; repeat or comma-separate for several")
+	window := on(cmdAnalysis).Duration("window", 0, "Also compute regeneration rates over successive windows of this length, e.g. 10m (0 = off)")
+	filterOutliers := on(cmdAnalysis).Bool("filter-outliers", false, "Reject positive delta spikes from the analysis")
+	resume := on(cmdMonitor).String("resume", "", "Continue a previous JSON log file and save back to it")
+	replayFile := on(cmdFlat).String("replay", "", "Analyze the snapshots of a previous JSON log file again instead of monitoring")
+	format := on(cmdReports).String("format", defaultFormat, "Output format: json, csv, both, md, html or influx")
+	outDir := on(cmdReports).String("out-dir", "", "Directory to write report files into")
+	outFile := on(cmdReports).String("out-file", "", "Report file name (default: timestamped name)")
+	stream := on(cmdMonitor).Bool("stream", false, "Append each snapshot to an NDJSON file as it is taken")
+	graph := on(cmdAnalysis).Bool("graph", false, "Print sparklines of energy and bandwidth availability after the summary")
+	quiet := on(cmdReports).Bool("quiet", false, "Don't print the header and snapshot lines, only the summary")
+	jsonStdout := on(cmdReports).Bool("json-stdout", false, "Write the JSON report to stdout, everything else to stderr")
+	timezone := on(cmdAll).String("timezone", "utc", "Time zone of console timestamps: utc, local or an IANA name")
+	numberFormat := on(cmdAll).String("number-format", output.NumberComma, "Thousands separator: "+strings.Join(output.NumberFormats(), ", "))
+	timezoneFilenames := on(cmdReports).Bool("timezone-filenames", false, "Also use --timezone for the timestamp in generated file names")
 
 	// Simulation flags
-	simulate := flag.Bool("simulate", false, "Run transaction simulation")
-	txCost := flag.Int64("tx-cost", 65000, "Energy cost per transaction for simulation")
-	targetTx := flag.Int("target-tx", 800, "Target transactions per day for simulation")
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s --address <TRON_ADDRESS> [options]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Monitor TRON account Energy and Bandwidth resources in real-time.\n\n")
-		fmt.Fprintf(os.Stderr, "Basic Flags:\n")
-		fmt.Fprintf(os.Stderr, "  -a, --address      TRON wallet address (required, format: T...)\n")
-		fmt.Fprintf(os.Stderr, "  -n, --node         TRON node URL (default: %s)\n", defaultNode)
-		fmt.Fprintf(os.Stderr, "  -d, --duration     Monitoring duration in seconds (default: %d)\n", defaultDuration)
-		fmt.Fprintf(os.Stderr, "  -i, --interval     Sampling interval in ms (default: %d)\n", defaultInterval)
-		fmt.Fprintf(os.Stderr, "\nAdvanced Flags:\n")
-		fmt.Fprintf(os.Stderr, "      --until-full   Monitor until resources are fully recovered\n")
-		fmt.Fprintf(os.Stderr, "      --max-duration Max duration for --until-full (default: %d)\n", defaultMaxDuration)
-		fmt.Fprintf(os.Stderr, "      --compare      Compare with previous log file\n")
-		fmt.Fprintf(os.Stderr, "\nSimulation Flags:\n")
-		fmt.Fprintf(os.Stderr, "      --simulate     Run transaction simulation after monitoring\n")
-		fmt.Fprintf(os.Stderr, "      --tx-cost      Energy cost per transaction (default: 65000)\n")
-		fmt.Fprintf(os.Stderr, "      --target-tx    Target transactions per day (default: 800)\n")
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  %s -a TXxx -d 60\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s -a TXxx --duration 3600 --interval 3000\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s -a TXxx --until-full --max-duration 86400\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s -a TXxx --simulate --tx-cost 65000 --target-tx 800\n", os.Args[0])
-	}
-
-	flag.Parse()
+	simulate := on(cmdMonitor|cmdAnalyze).Bool("simulate", false, "Run transaction simulation")
+	txCost := on(cmdAnalysis).String("tx-cost", "65000", "Energy cost per transaction, or a weighted mix like 65000:0.7,131000:0.3")
+	bwCost := on(cmdAnalysis).Int64("bw-cost", 0, "Bandwidth cost per transaction for simulation (0 = skip)")
+	targetTx := on(cmdAnalysis).Int("target-tx", 800, "Target transactions per day for simulation")
+	forecastTx := on(cmdAnalysis).Int("forecast-tx", 0, "Forecast when energy for this many transactions is available")
+	energyFee := on(cmdAnalysis).Int64("energy-fee", 0, "Energy price in sun for burn estimates (0 = query the node)")
+	minTxPerDay := on(cmdAnalysis).Float64("min-tx-per-day", 0, "Exit with code 2 if sustained tx/day is below this (0 = off)")
+	minEnergy := on(cmdAnalysis).Int64("min-energy", 0, "Exit with code 2 if available energy at the end is below this (0 = off)")
+	configFile := on(cmdAll).String("config", "", "Read flags from a YAML file (keys are long flag names)")
+	metricsAddr := on(cmdMonitor).String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9100)")
+	webhookURL := on(cmdMonitor).String("webhook", "", "POST a JSON event to this URL on full recovery or a crossed --webhook-* threshold")
+	webhookEnergy := on(cmdMonitor).Int64("webhook-energy", 0, "Fire a webhook event when available energy rises to this (0 = off)")
+	webhookBandwidth := on(cmdMonitor).Int64("webhook-bandwidth", 0, "Fire a webhook event when available bandwidth rises to this (0 = off)")
+	influxURL := on(cmdMonitor).String("influx-url", "", "POST the snapshots as InfluxDB line protocol to this write URL")
+	influxToken := on(cmdMonitor).String("influx-token", "", "InfluxDB API token (env: "+influxTokenEnv+")")
+	influxMeasurement := on(cmdAnalysis).String("influx-measurement", output.DefaultInfluxMeasurement, "InfluxDB measurement name")
+	influxAddressTag := on(cmdAnalysis).String("influx-address-tag", "address", "InfluxDB tag key for the address")
+	influxNodeTag := on(cmdAnalysis).String("influx-node-tag", "node", "InfluxDB tag key for the node")
+
+	fs.Usage = func() { printUsage(cmd) }
+
+	fs.Parse(args)
+
+	// simulate and compare take their reports as arguments, the others none
+	switch {
+	case cmd&(cmdSimulate|cmdAnalyze) != 0 && fs.NArg() != 1:
+		fmt.Fprintf(os.Stderr, "Error: %s needs exactly one report file\n", cmd)
+		os.Exit(1)
+	case cmd == cmdCompare && fs.NArg() == 0:
+		fmt.Fprintln(os.Stderr, "Error: compare needs report files, globs or directories")
+		os.Exit(1)
+	case cmd&(cmdMonitor|cmdOnce) != 0 && fs.NArg() > 0:
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	if *configFile != "" {
+		if err := applyConfigFile(fs, other, *configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Build config
 	cfg := models.Config{
-		Address:     *address,
-		Node:        *node,
+		Addresses:   addresses.values,
+		Nodes:       nodes.values,
+		APIKey:      *apiKey,
+		Timeout:     *timeout,
+		Retries:     *retries,
+		Backoff:     *backoff,
+		Solidity:    *solidity,
+		MaxRPS:      *maxRPS,
 		Duration:    *duration,
 		IntervalMs:  *interval,
 		UntilFull:   *untilFull,
+		Once:        *onceFlag,
 		MaxDuration: *maxDuration,
-		CompareFile: *compareFile,
-		Simulate:    *simulate,
-		TxCost:      *txCost,
-		TargetTx:    *targetTx,
+		RecoveryTarget: tronres.RecoveryTarget{
+			Resource:         *recoveryTarget,
+			ThresholdPercent: *recoveryThreshold,
+		},
+		CompareFiles:     compareFiles.values,
+		Resume:           *resume,
+		Replay:           *replayFile,
+		FilterOutliers:   *filterOutliers,
+		Window:           *window,
+		Simulate:         *simulate,
+		BWCost:           *bwCost,
+		TargetTx:         *targetTx,
+		ForecastTx:       *forecastTx,
+		EnergyFee:        *energyFee,
+		MinTxPerDay:      *minTxPerDay,
+		MinEnergy:        *minEnergy,
+		MetricsAddr:      *metricsAddr,
+		WebhookURL:       *webhookURL,
+		WebhookEnergy:    *webhookEnergy,
+		WebhookBandwidth: *webhookBandwidth,
+		InfluxURL:        *influxURL,
+		InfluxToken:      *influxToken,
+		Influx: output.InfluxOptions{
+			Measurement: *influxMeasurement,
+			AddressTag:  *influxAddressTag,
+			NodeTag:     *influxNodeTag,
+		},
+		Format:            *format,
+		Graph:             *graph,
+		Quiet:             *quiet,
+		JSONStdout:        *jsonStdout,
+		TimezoneFilenames: *timezoneFilenames,
+		NumberFormat:      *numberFormat,
+		OutDir:            *outDir,
+		OutFile:           *outFile,
+		Stream:            *stream,
 	}
 
+	cost, mix, err := parseTxCost(*txCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.TxCost, cfg.TxMix = cost, mix
+
 	// Handle shorthand flags
-	if cfg.Address == "" {
-		cfg.Address = *addressShort
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv(apiKeyEnv)
 	}
-	if *nodeShort != "" {
-		cfg.Node = *nodeShort
+	if cfg.InfluxToken == "" {
+		cfg.InfluxToken = os.Getenv(influxTokenEnv)
 	}
 	if *durationShort > 0 {
 		cfg.Duration = *durationShort
@@ -97,17 +237,133 @@ func main() {
 		cfg.IntervalMs = *intervalShort
 	}
 
-	// Validate address
-	if cfg.Address == "" {
+	// The commands stand for the flags of the flat invocation
+	switch cmd {
+	case cmdOnce:
+		cfg.Once = true
+	case cmdSimulate:
+		cfg.Replay = fs.Arg(0)
+		cfg.Simulate = true
+	case cmdAnalyze:
+		cfg.Replay = fs.Arg(0)
+	}
+
+	// A resumed session continues the address of the previous report
+	var resumed *tronres.MonitorReport
+	if cfg.Resume != "" {
+		report, err := loadReport(cfg.Resume)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to resume: %v\n", err)
+			os.Exit(1)
+		}
+		if len(report.Snapshots) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s has no snapshots to resume from\n", cfg.Resume)
+			os.Exit(1)
+		}
+		if len(cfg.Addresses) == 0 {
+			cfg.Addresses = []string{report.Metadata.Address}
+		}
+		if len(cfg.Addresses) != 1 || !sameAddress(cfg.Addresses[0], report.Metadata.Address) {
+			fmt.Fprintf(os.Stderr, "Error: %s was recorded for %s, --resume can't monitor other addresses\n", cfg.Resume, report.Metadata.Address)
+			os.Exit(1)
+		}
+		if cfg.Stream || cfg.OutFile != "" || cfg.OutDir != "" {
+			fmt.Fprintln(os.Stderr, "Error: --resume saves back to the resumed file, it can't be combined with --stream, --out-file or --out-dir")
+			os.Exit(1)
+		}
+		resumed = &report
+	}
+
+	// A replay analyses the address of the recorded report
+	var replayed *tronres.MonitorReport
+	if cfg.Replay != "" {
+		if cfg.Resume != "" || cfg.Stream {
+			fmt.Fprintln(os.Stderr, "Error: --replay can't be combined with --resume or --stream")
+			os.Exit(1)
+		}
+		load := loadReport
+		if cmd == cmdAnalyze {
+			load = loadSnapshots
+		}
+		report, err := load(cfg.Replay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to replay: %v\n", err)
+			os.Exit(1)
+		}
+		if len(report.Snapshots) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s has no snapshots to replay\n", cfg.Replay)
+			os.Exit(1)
+		}
+		// Snapshots written by another tool don't say whose they are
+		if report.Metadata.Address == "" && cmd == cmdAnalyze {
+			if len(cfg.Addresses) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: %s names no address, give it with -a\n", cfg.Replay)
+				os.Exit(1)
+			}
+			report.Metadata.Address = cfg.Addresses[0]
+		}
+		if len(cfg.Addresses) == 0 {
+			cfg.Addresses = []string{report.Metadata.Address}
+		}
+		if len(cfg.Addresses) != 1 || !sameAddress(cfg.Addresses[0], report.Metadata.Address) {
+			fmt.Fprintf(os.Stderr, "Error: %s was recorded for %s, --replay can't analyze other addresses\n", cfg.Replay, report.Metadata.Address)
+			os.Exit(1)
+		}
+		if cmd == cmdAnalyze {
+			rederive(&report)
+		}
+		replayed = &report
+	}
+
+	if cfg.Once && (cfg.UntilFull || cfg.Resume != "" || cfg.Replay != "" || cfg.Stream) {
+		fmt.Fprintln(os.Stderr, "Error: --once can't be combined with --until-full, --resume, --replay or --stream")
+		os.Exit(1)
+	}
+
+	// Validate address, compare only uses it to pick reports
+	if len(cfg.Addresses) == 0 && cmd != cmdCompare {
 		fmt.Fprintln(os.Stderr, "Error: address is required")
-		flag.Usage()
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	if err := client.ValidateAddress(cfg.Address); err != nil {
+	seen := make(map[string]bool, len(cfg.Addresses))
+	for _, address := range cfg.Addresses {
+		if err := tronres.ValidateAddress(address); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// The base58 and hex forms of an account are the same address
+		normalized, err := tronres.NormalizeAddress(address)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if seen[normalized] {
+			fmt.Fprintf(os.Stderr, "Error: address %s is given more than once\n", address)
+			os.Exit(1)
+		}
+		seen[normalized] = true
+	}
+
+	// Resolve the network preset even when --node overrides it, so a typo
+	// in the network name is never silently ignored
+	networkURL, err := tronres.NetworkURL(*network)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if len(cfg.Nodes) == 0 {
+		cfg.Nodes = []string{networkURL}
+	}
+	for i, node := range cfg.Nodes {
+		normalized, err := tronres.NormalizeNodeURL(node)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Nodes[i] = normalized
+	}
 
 	// Validate duration
 	if cfg.Duration <= 0 {
@@ -120,136 +376,380 @@ func main() {
 		fmt.Fprintln(os.Stderr, "Error: interval must be at least 100ms")
 		os.Exit(1)
 	}
+	if cfg.MaxRPS < 0 {
+		fmt.Fprintln(os.Stderr, "Error: max-rps must not be negative")
+		os.Exit(1)
+	}
+	// Every address is polled once per interval, stretch the interval
+	// rather than let the limiter push the samples out of step
+	if pollRPS := float64(len(cfg.Addresses)) * 1000 / float64(cfg.IntervalMs); cfg.MaxRPS > 0 && !cfg.Once && pollRPS > cfg.MaxRPS {
+		adjusted := int(math.Ceil(float64(len(cfg.Addresses)) * 1000 / cfg.MaxRPS))
+		fmt.Fprintf(os.Stderr, "Warning: polling %d address(es) every %dms needs %.1f requests/s, above --max-rps %g; sampling every %dms instead\n",
+			len(cfg.Addresses), cfg.IntervalMs, pollRPS, cfg.MaxRPS, adjusted)
+		cfg.IntervalMs = adjusted
+	}
+	if rest := cfg.Duration * 1000 % cfg.IntervalMs; rest != 0 && !cfg.UntilFull && !cfg.Once {
+		fmt.Fprintf(os.Stderr, "Warning: %ds is not a multiple of the %dms interval, the last of %d samples is taken %dms after the previous one\n",
+			cfg.Duration, cfg.IntervalMs, tronres.SampleCount(cfg.Duration, cfg.IntervalMs), rest)
+	}
 
-	// Run the monitor
-	if err := run(cfg); err != nil {
-		output.PrintError(err)
+	if cfg.RecoveryTarget.ThresholdPercent < 0 || cfg.RecoveryTarget.ThresholdPercent >= 100 {
+		fmt.Fprintln(os.Stderr, "Error: recovery-threshold must be at least 0 and below 100")
 		os.Exit(1)
 	}
-}
+	if !slices.Contains([]string{tronres.RecoverBoth, tronres.RecoverEnergy, tronres.RecoverBandwidth}, cfg.RecoveryTarget.Resource) {
+		fmt.Fprintf(os.Stderr, "Error: unknown recovery-target %q (expected both, energy or bandwidth)\n", cfg.RecoveryTarget.Resource)
+		os.Exit(1)
+	}
+	if !cfg.UntilFull && !cfg.Once && (cfg.RecoveryTarget.ThresholdPercent > 0 || cfg.RecoveryTarget.Resource != tronres.RecoverBoth) {
+		fmt.Fprintln(os.Stderr, "Warning: --recovery-threshold and --recovery-target only apply to --until-full")
+	}
 
-func run(cfg models.Config) error {
-	// Setup context with cancellation for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	if cfg.Window < 0 || (cfg.Window > 0 && cfg.Window < time.Second) {
+		fmt.Fprintln(os.Stderr, "Error: window must be at least 1s")
+		os.Exit(1)
+	}
 
-	// Handle Ctrl+C
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Confirmed data trails the chain, the last minute of a short run is missing
+	if cfg.Solidity && !cfg.UntilFull && !cfg.Once && cfg.Duration < solidityMinDuration {
+		fmt.Fprintf(os.Stderr, "Warning: --solidity data lags the chain by about a minute, a %ds run may undercount recent activity\n", cfg.Duration)
+	}
 
-	// Create client and monitor
-	c := client.New(cfg.Node)
-	m := monitor.NewWithInterval(c, cfg.Address, cfg.Duration, cfg.IntervalMs)
+	// Validate simulation costs
+	if cfg.TxCost < 0 || cfg.BWCost < 0 || cfg.EnergyFee < 0 {
+		fmt.Fprintln(os.Stderr, "Error: tx-cost, bw-cost and energy-fee must not be negative")
+		os.Exit(1)
+	}
+	if cfg.ForecastTx < 0 {
+		fmt.Fprintln(os.Stderr, "Error: forecast-tx must not be negative")
+		os.Exit(1)
+	}
+	if cfg.ForecastTx > 0 && cfg.TxCost == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --forecast-tx needs a positive tx-cost")
+		os.Exit(1)
+	}
+	if cfg.MinTxPerDay < 0 || cfg.MinEnergy < 0 {
+		fmt.Fprintln(os.Stderr, "Error: min-tx-per-day and min-energy must not be negative")
+		os.Exit(1)
+	}
+	if cfg.MinTxPerDay > 0 && cfg.TxCost == 0 && cfg.BWCost == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --min-tx-per-day needs a positive tx-cost or bw-cost")
+		os.Exit(1)
+	}
+	if cfg.Simulate && cfg.TxCost == 0 && cfg.BWCost == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --simulate needs a positive tx-cost or bw-cost")
+		os.Exit(1)
+	}
 
-	startTime := time.Now()
-	output.PrintHeader(cfg.Address, cfg.Node, cfg.Duration, cfg.IntervalMs, startTime)
+	// Validate connection settings
+	if cfg.Timeout < 0 || cfg.Backoff < 0 {
+		fmt.Fprintln(os.Stderr, "Error: timeout and backoff must not be negative")
+		os.Exit(1)
+	}
+	if cfg.Retries < 1 {
+		fmt.Fprintln(os.Stderr, "Error: retries must be at least 1")
+		os.Exit(1)
+	}
+	if *proxy != "" {
+		u, err := parseProxy(*proxy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Proxy = u
+	}
 
-	// Channel to collect snapshots
-	var snapshots []models.ResourceSnapshot
-	done := make(chan error, 1)
+	// One file name can't hold the reports of several addresses
+	if len(cfg.Addresses) > 1 && cfg.OutFile != "" {
+		fmt.Fprintln(os.Stderr, "Error: --out-file can't be used with several addresses, use --out-dir")
+		os.Exit(1)
+	}
 
-	go func() {
-		var err error
-		if cfg.UntilFull {
-			snapshots, err = m.RunUntilFull(ctx, cfg.MaxDuration, func(snapshot models.ResourceSnapshot, index int) {
-				output.PrintSnapshot(snapshot, index)
-			})
-		} else {
-			snapshots, err = m.Run(ctx, func(snapshot models.ResourceSnapshot, index int) {
-				output.PrintSnapshot(snapshot, index)
-			})
+	location, err := parseTimezone(*timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Location = location
+
+	if !slices.Contains(output.NumberFormats(), cfg.NumberFormat) {
+		fmt.Fprintf(os.Stderr, "Error: unknown number format %q (expected %s)\n", cfg.NumberFormat, strings.Join(output.NumberFormats(), ", "))
+		os.Exit(1)
+	}
+
+	// Validate output format
+	switch cfg.Format {
+	case formatJSON, formatCSV, formatBoth, formatMD, formatHTML, formatInflux:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (expected json, csv, both, md, html or influx)\n", cfg.Format)
+		os.Exit(1)
+	}
+	if cfg.Influx.Measurement == "" || cfg.Influx.AddressTag == "" || cfg.Influx.NodeTag == "" {
+		fmt.Fprintln(os.Stderr, "Error: --influx-measurement, --influx-address-tag and --influx-node-tag must not be empty")
+		os.Exit(1)
+	}
+	if cfg.InfluxURL != "" {
+		if u, err := url.Parse(cfg.InfluxURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fmt.Fprintf(os.Stderr, "Error: invalid --influx-url %q (expected an http or https URL)\n", cfg.InfluxURL)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.WebhookURL != "" {
+		if u, err := url.Parse(cfg.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fmt.Fprintf(os.Stderr, "Error: invalid --webhook %q (expected an http or https URL)\n", cfg.WebhookURL)
+			os.Exit(1)
+		}
+	}
+	if cfg.WebhookEnergy < 0 || cfg.WebhookBandwidth < 0 {
+		fmt.Fprintln(os.Stderr, "Error: webhook-energy and webhook-bandwidth must not be negative")
+		os.Exit(1)
+	}
+	if cfg.WebhookURL == "" && (cfg.WebhookEnergy > 0 || cfg.WebhookBandwidth > 0) {
+		fmt.Fprintln(os.Stderr, "Error: --webhook-energy and --webhook-bandwidth need --webhook")
+		os.Exit(1)
+	}
+
+	output.Configure(output.ConsoleOptions{
+		Quiet:        cfg.Quiet,
+		Stderr:       cfg.JSONStdout,
+		Location:     cfg.Location,
+		NumberFormat: cfg.NumberFormat,
+	})
+
+	// --once writes a report only for an explicit --format json, also from the config file
+	if cfg.Once && cfg.Format != formatJSON && formatSet(fs) {
+		fmt.Fprintf(os.Stderr, "Error: --once can only save --format json, not %s\n", cfg.Format)
+		os.Exit(1)
+	}
+
+	// Run the monitor, take a single snapshot, analyze the recorded snapshots
+	// again or compare saved reports
+	switch {
+	case cmd == cmdCompare:
+		err = compareReports(cfg, fs.Args())
+	case cfg.Once:
+		err = once(cfg, formatSet(fs))
+	case replayed != nil:
+		err = replay(cfg, *replayed)
+	default:
+		err = run(cfg, resumed)
+	}
+	if err != nil {
+		// Failures are printed in the summary, the exit code tells automation
+		if errors.Is(err, errThresholdsNotMet) {
+			os.Exit(2)
+		}
+		printRunError(err)
+		if errors.Is(err, errNoSamples) {
+			os.Exit(3)
+		}
+		os.Exit(1)
+	}
+}
+
+// formatSet reports whether --format was given on the command line or in the config file
+func formatSet(fs *flag.FlagSet) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "format" {
+			set = true
+		}
+	})
+	return set
+}
+
+// printRunError prints the errors returned by run, explaining unknown
+// accounts separately
+func printRunError(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			printRunError(e)
 		}
-		done <- err
-	}()
+		return
+	}
+
+	var apiErr *tronres.APIError
+	var addrErr *addressError
+	if errors.As(err, &apiErr) && apiErr.NotFound() && errors.As(err, &addrErr) {
+		output.PrintAccountNotFound(addrErr.address, apiErr)
+	} else {
+		output.PrintError(err)
+	}
+}
 
-	// Wait for completion or interrupt
-	var runErr error
-	select {
-	case <-sigChan:
-		output.PrintInterrupted()
-		cancel()
-		select {
-		case runErr = <-done:
-		case <-time.After(2 * time.Second):
+// resourcePrices returns the energy and bandwidth burn prices from the node's
+// chain parameters. A positive energyFee overrides the on-chain energy price.
+func resourcePrices(c *tronres.Client, energyFee int64) tronres.ResourcePrices {
+	prices := tronres.ResourcePrices{
+		EnergyFeeSun:    energyFee,
+		BandwidthFeeSun: defaultBWFee,
+	}
+
+	// Called after monitoring, when the run's context may already be
+	// cancelled. A single short attempt, so an interrupted run isn't held
+	// up by retries against every fallback node.
+	ctx, cancel := context.WithTimeout(context.Background(), pricesTimeout)
+	defer cancel()
+	params, err := c.SingleAttempt().GetChainParameters(ctx)
+	if err != nil {
+		if energyFee == 0 {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to fetch chain parameters, TRX burn estimates unavailable (use --energy-fee): %v\n", err)
 		}
-	case runErr = <-done:
+		return prices
+	}
+
+	if v, ok := params.Value("getEnergyFee"); ok && energyFee == 0 {
+		prices.EnergyFeeSun = v
+	}
+	if v, ok := params.Value("getTransactionFee"); ok {
+		prices.BandwidthFeeSun = v
 	}
 
-	endTime := time.Now()
+	return prices
+}
 
-	// Even if interrupted, save what we have
-	if len(snapshots) > 0 {
-		analysis := monitor.Analyze(snapshots, cfg.Duration)
+// saveReport writes the report in the requested format(s) and returns the
+// paths of the files that were written. Failures are reported as warnings.
+// With an open stream the snapshots are already on disk, so JSON output
+// only carries the metadata and analysis.
+func saveReport(report tronres.MonitorReport, cfg models.Config, dest output.Destination, stream *output.StreamWriter) []string {
+	format := cfg.Format
+	var filenames []string
+	if stream != nil {
+		filenames = append(filenames, stream.Filename())
+	}
 
-		// Build and save report - use actual duration from analysis
-		actualDurationInt := int(analysis.ActualDurationSec)
-		if actualDurationInt < 1 {
-			actualDurationInt = 1
+	if format == formatJSON || format == formatBoth {
+		save := output.SaveJSON
+		if stream != nil {
+			save = output.SaveAnalysisJSON
 		}
-		report := output.BuildReport(cfg.Address, cfg.Node, startTime, endTime, actualDurationInt, snapshots, analysis)
-		report.Metadata.IntervalMs = cfg.IntervalMs
+		filename, err := save(report, dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to save JSON: %v\n", err)
+		} else {
+			filenames = append(filenames, filename)
+		}
+	}
 
-		filename, saveErr := output.SaveJSON(report)
-		if saveErr != nil {
-			fmt.Fprintf(os.Stderr, "\nWarning: failed to save JSON: %v\n", saveErr)
+	if format == formatCSV || format == formatBoth {
+		csvFiles, err := output.SaveCSV(report, dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to save CSV: %v\n", err)
+		}
+		filenames = append(filenames, csvFiles...)
+	}
+
+	if format == formatMD {
+		filename, err := output.SaveMarkdown(report, dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to save Markdown: %v\n", err)
 		} else {
-			output.PrintSummary(analysis, filename)
+			filenames = append(filenames, filename)
 		}
+	}
 
-		// Run simulation if requested
-		if cfg.Simulate && len(snapshots) > 0 {
-			sim := monitor.Simulate(snapshots[len(snapshots)-1], analysis, cfg.TxCost, cfg.TargetTx)
-			output.PrintSimulation(sim)
+	if format == formatHTML {
+		filename, err := output.SaveHTML(report, dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to save HTML: %v\n", err)
+		} else {
+			filenames = append(filenames, filename)
 		}
+	}
 
-		// Compare with previous file if requested
-		if cfg.CompareFile != "" {
-			if err := compareWithPrevious(cfg.CompareFile, analysis); err != nil {
-				fmt.Fprintf(os.Stderr, "\nWarning: failed to compare: %v\n", err)
-			}
+	if format == formatInflux {
+		filename, err := output.SaveInflux(report, dest, cfg.Influx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to save line protocol: %v\n", err)
+		} else {
+			filenames = append(filenames, filename)
 		}
 	}
 
-	return runErr
+	return filenames
 }
 
-func compareWithPrevious(filename string, current models.Analysis) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+// destination returns where the reports of cfg are written
+func destination(cfg models.Config) output.Destination {
+	dest := output.Destination{Dir: cfg.OutDir, File: cfg.OutFile}
+	if cfg.TimezoneFilenames {
+		dest.Location = cfg.Location
 	}
 
-	var prev models.MonitorReport
-	if err := json.Unmarshal(data, &prev); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+	// An absolute --out-file names the main output, the stream in stream mode
+	switch {
+	case cfg.Stream:
+		dest.Primary = ".ndjson"
+	case cfg.Format == formatJSON || cfg.Format == formatBoth:
+		dest.Primary = ".json"
+	case cfg.Format == formatCSV:
+		dest.Primary = ".csv"
+	case cfg.Format == formatMD:
+		dest.Primary = ".md"
+	case cfg.Format == formatHTML:
+		dest.Primary = ".html"
+	case cfg.Format == formatInflux:
+		dest.Primary = ".lp"
 	}
+	return dest
+}
 
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("Comparison with: %s\n", filename)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+// parseTimezone resolves a --timezone value: utc, local or an IANA zone name
+func parseTimezone(name string) (*time.Location, error) {
+	switch strings.ToLower(name) {
+	case "utc", "":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
 
-	prevAnalysis := prev.Analysis
+// parseProxy validates a --proxy URL. The schemes are the ones net/http can dial through.
+func parseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid --proxy %q: scheme must be http, https, socks5 or socks5h", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid --proxy %q: missing host", raw)
+	}
+	return u, nil
+}
 
-	fmt.Printf("Energy Regen Rate:    %.1f -> %.1f /sec (delta: %+.1f)\n",
-		prevAnalysis.EnergyRegenRatePerSec,
-		current.EnergyRegenRatePerSec,
-		current.EnergyRegenRatePerSec-prevAnalysis.EnergyRegenRatePerSec)
+// loadReport reads a JSON report written by a previous run
+func loadReport(filename string) (tronres.MonitorReport, error) {
+	var report tronres.MonitorReport
 
-	fmt.Printf("Energy Consume Rate:  %.1f -> %.1f /sec (delta: %+.1f)\n",
-		prevAnalysis.EnergyConsumeRatePerSec,
-		current.EnergyConsumeRatePerSec,
-		current.EnergyConsumeRatePerSec-prevAnalysis.EnergyConsumeRatePerSec)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return report, fmt.Errorf("failed to read file: %w", err)
+	}
 
-	fmt.Printf("Bandwidth Regen Rate: %.1f -> %.1f /sec (delta: %+.1f)\n",
-		prevAnalysis.BandwidthRegenRatePerSec,
-		current.BandwidthRegenRatePerSec,
-		current.BandwidthRegenRatePerSec-prevAnalysis.BandwidthRegenRatePerSec)
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse JSON: %w", err)
+	}
 
-	fmt.Printf("Tx/day (65k):         %.0f -> %.0f (delta: %+.0f)\n",
-		prevAnalysis.TxPerDay65k,
-		current.TxPerDay65k,
-		current.TxPerDay65k-prevAnalysis.TxPerDay65k)
+	return report, nil
+}
 
-	return nil
+// sameAddress reports whether a and b are the same address, in any format
+func sameAddress(a, b string) bool {
+	na, errA := tronres.NormalizeAddress(a)
+	nb, errB := tronres.NormalizeAddress(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return na == nb
 }
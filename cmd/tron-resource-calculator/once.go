@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// once prints a single snapshot of every address and returns, without the
+// monitoring loop or the analysis. A report is only saved when saveJSON is
+// set (an explicit --format json); --json-stdout writes it to stdout.
+func once(cfg models.Config, saveJSON bool) error {
+	c := newClient(cfg)
+	dest := destination(cfg)
+
+	var errs []error
+	for _, address := range cfg.Addresses {
+		snapshot, err := tronres.TakeSnapshot(context.Background(), c, address)
+		if err != nil {
+			errs = append(errs, &addressError{address: address, err: fmt.Errorf("%w: %w", errNoSamples, err)})
+			continue
+		}
+
+		tag := ""
+		if len(cfg.Addresses) > 1 {
+			tag = output.ShortAddress(address)
+		}
+		output.PrintSnapshot(snapshot, 0, tag)
+
+		if !saveJSON && !cfg.JSONStdout {
+			continue
+		}
+		snapshots := []tronres.Snapshot{snapshot}
+		report := output.BuildReport(address, cfg.Nodes[0], snapshot.Timestamp, snapshot.Timestamp, 0, snapshots, tronres.Analysis{})
+		report.Metadata.NodesUsed = c.NodesUsed()
+		report.Metadata.Endpoint = c.ResourceEndpoint()
+		report.Metadata.AttemptedSamples = 1
+
+		if saveJSON {
+			if filename, err := output.SaveJSON(report, dest); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save JSON: %v\n", err)
+			} else {
+				output.PrintSaved(filename)
+			}
+		}
+		if cfg.JSONStdout {
+			if err := output.WriteJSON(os.Stdout, report); err != nil {
+				return err
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// replay runs the snapshots of a previous report through the analysis again,
+// with the current analysis, simulation and comparison flags. No node is
+// contacted, so burn estimates need --energy-fee. A new report is only saved
+// when --out-file or --out-dir is given.
+func replay(cfg models.Config, report tronres.MonitorReport) error {
+	output.PrintReplaying(cfg.Replay, len(report.Snapshots), report.Snapshots[0].Timestamp, report.Snapshots[len(report.Snapshots)-1].Timestamp)
+
+	prices := tronres.ResourcePrices{
+		EnergyFeeSun:    cfg.EnergyFee,
+		BandwidthFeeSun: defaultBWFee,
+	}
+	report.Analysis = analyzeSnapshots(cfg, report.Snapshots, report.Account, report.Metadata.IntervalMs, report.Metadata.AttemptedSamples, prices)
+
+	var filenames []string
+	if cfg.OutFile != "" || cfg.OutDir != "" {
+		filenames = saveReport(report, cfg, destination(cfg), nil)
+	}
+
+	// The replayed file is the same run, never a previous one
+	exclude := append([]string{cfg.Replay}, filenames...)
+	thresholdsMet := printResults(cfg, "", report, prices, filenames, exclude)
+
+	if cfg.JSONStdout {
+		if err := output.WriteJSON(os.Stdout, report); err != nil {
+			return err
+		}
+	}
+
+	if !thresholdsMet {
+		return errThresholdsNotMet
+	}
+	return nil
+}
+
+// loadSnapshots reads a file for the analyze command: a report, possibly
+// written by another tool with nothing but its "snapshots", or a bare array
+// of snapshots
+func loadSnapshots(filename string) (tronres.MonitorReport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return tronres.MonitorReport{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return loadReport(filename)
+	}
+
+	var report tronres.MonitorReport
+	if err := json.Unmarshal(data, &report.Snapshots); err != nil {
+		return report, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return report, nil
+}
+
+// rederive recomputes the snapshots of report from the limits and used
+// amounts and fills in the metadata a snapshots-only file lacks, so the
+// analysis doesn't rest on anything but the raw samples
+func rederive(report *tronres.MonitorReport) {
+	report.Snapshots = tronres.DeriveSnapshots(report.Snapshots)
+
+	first, last := report.Snapshots[0], report.Snapshots[len(report.Snapshots)-1]
+	if report.Metadata.StartTime.IsZero() {
+		report.Metadata.StartTime = first.Timestamp
+	}
+	if report.Metadata.EndTime.IsZero() {
+		report.Metadata.EndTime = last.Timestamp
+	}
+	if report.Metadata.DurationSeconds == 0 {
+		report.Metadata.DurationSeconds = int((last.ElapsedMs - first.ElapsedMs) / 1000)
+	}
+	report.Metadata.SamplesCount = len(report.Snapshots)
+	report.Metadata.ActualIntervalMeanMs, report.Metadata.ActualIntervalStddevMs = tronres.IntervalStats(report.Snapshots)
+	report.Metadata.ClockAnomalies = tronres.ClockAnomalies(report.Snapshots)
+}
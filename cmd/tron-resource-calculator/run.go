@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/metrics"
+	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/internal/webhook"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// addressError ties a monitoring error to the address it happened for
+type addressError struct {
+	address string
+	err     error
+}
+
+func (e *addressError) Error() string {
+	return e.address + ": " + e.err.Error()
+}
+
+func (e *addressError) Unwrap() error {
+	return e.err
+}
+
+// errThresholdsNotMet is returned by run when an account fails --min-tx-per-day or --min-energy
+var errThresholdsNotMet = errors.New("alert thresholds not met")
+
+// errNoSamples is returned by run for an address none of whose polls succeeded
+var errNoSamples = errors.New("no samples collected")
+
+// session monitors one address. Snapshots are collected from the callback,
+// so the data gathered so far can be saved after an interrupt.
+type session struct {
+	address string
+	tag     string // console prefix, empty when only one address is monitored
+	monitor *tronres.Monitor
+	account *tronres.AccountInfo
+	stream  *output.StreamWriter
+
+	mu        sync.Mutex
+	snapshots []tronres.Snapshot
+	// streamed is the stream file in stream mode. The snapshots written to
+	// it are not kept in memory but read back for the analysis.
+	streamed string
+}
+
+func (s *session) onSnapshot(header func(tronres.Snapshot), recorder *metrics.Recorder, notifier *webhook.Notifier) func(snapshot tronres.Snapshot, index int) {
+	return func(snapshot tronres.Snapshot, index int) {
+		header(snapshot)
+		output.PrintSnapshot(snapshot, index, s.tag)
+		if snapshot.Failed {
+			return
+		}
+
+		s.mu.Lock()
+		if s.stream != nil {
+			if err := s.stream.Write(snapshot); err != nil {
+				// Kept in memory from now on, next to the ones already on disk
+				fmt.Fprintf(os.Stderr, "Warning: %v, streaming disabled\n", err)
+				s.stream.Close()
+				s.stream = nil
+			}
+		}
+		if s.stream == nil {
+			s.snapshots = append(s.snapshots, snapshot)
+		}
+		s.mu.Unlock()
+
+		if recorder != nil {
+			recorder.Observe(s.address, snapshot)
+		}
+		if notifier != nil {
+			notifier.Observe(s.address, snapshot)
+		}
+	}
+}
+
+// collected returns the snapshots collected so far, the streamed ones
+// read back from the stream file
+func (s *session) collected() []tronres.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snapshots []tronres.Snapshot
+	if s.streamed != "" {
+		streamed, err := output.ReadStream(s.streamed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		snapshots = streamed
+	}
+	return append(snapshots, s.snapshots...)
+}
+
+// run monitors the configured addresses and saves a report per address.
+// resumed, if not nil, is a previous report of the only address that the
+// new snapshots are appended to.
+func run(cfg models.Config, resumed *tronres.MonitorReport) error {
+	// Setup context with cancellation for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle Ctrl+C
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// One client is shared by all monitors, so they fail over together
+	c := newClient(cfg)
+
+	sessions := make([]*session, len(cfg.Addresses))
+	headers := make([]output.HeaderAddress, len(cfg.Addresses))
+	for i, address := range cfg.Addresses {
+		s := &session{
+			address: address,
+			monitor: tronres.NewMonitorWithInterval(c, address, cfg.Duration, cfg.IntervalMs),
+		}
+		s.monitor.SetRecoveryTarget(cfg.RecoveryTarget)
+		s.monitor.DiscardSnapshots() // the session collects them from the callback
+		if len(cfg.Addresses) > 1 {
+			s.tag = output.ShortAddress(address)
+		}
+
+		// Staking info is informational, monitoring works without it
+		if resp, err := c.GetAccount(ctx, address); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to fetch account info for %s: %v\n", address, err)
+		} else {
+			info := resp.AccountInfo()
+			s.account = &info
+		}
+
+		if resumed != nil {
+			s.snapshots = append(s.snapshots, resumed.Snapshots...)
+			s.monitor.ResumeFrom(resumed.Snapshots[len(resumed.Snapshots)-1])
+		}
+
+		sessions[i] = s
+		headers[i] = output.HeaderAddress{Address: address, Account: s.account}
+	}
+
+	startTime := time.Now()
+	if resumed != nil {
+		last := resumed.Snapshots[len(resumed.Snapshots)-1]
+		output.PrintResuming(cfg.Resume, len(resumed.Snapshots), last.Timestamp)
+		startTime = resumed.Metadata.StartTime
+	}
+
+	// The network totals are the same for every account, the header shows
+	// those of the first poll and is printed before its snapshot line
+	var headerOnce sync.Once
+	header := func(first tronres.Snapshot) {
+		headerOnce.Do(func() {
+			var network *tronres.Snapshot
+			if !first.Failed {
+				network = &first
+			}
+			output.PrintHeader(headers, network, strings.Join(cfg.Nodes, ", "), cfg.Duration, cfg.IntervalMs, startTime)
+		})
+	}
+
+	// Optional Prometheus endpoint, stopped by the same cancel as monitoring
+	var recorder *metrics.Recorder
+	if cfg.MetricsAddr != "" {
+		recorder = metrics.NewRecorder()
+		stopped, err := metrics.Serve(ctx, cfg.MetricsAddr, recorder)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			cancel()
+			<-stopped
+		}()
+	}
+
+	// Webhook events are delivered in the background, the pending ones
+	// are waited for before returning
+	var notifier *webhook.Notifier
+	if cfg.WebhookURL != "" {
+		notifier = webhook.NewNotifier(cfg.WebhookURL, webhook.Options{
+			EnergyThreshold:    cfg.WebhookEnergy,
+			BandwidthThreshold: cfg.WebhookBandwidth,
+			RecoveryTarget:     cfg.RecoveryTarget,
+		})
+		defer notifier.Wait()
+	}
+
+	// In stream mode every snapshot goes to disk right away
+	dest := destination(cfg)
+	if resumed != nil {
+		dest = output.Destination{File: cfg.Resume, Primary: ".json"}
+	}
+	if cfg.Stream {
+		for _, s := range sessions {
+			stream, err := output.OpenStream(dest, s.address, startTime)
+			if err != nil {
+				return err
+			}
+			s.stream = stream
+			s.streamed = stream.Filename()
+			defer stream.Close()
+		}
+	}
+
+	// All addresses are sampled concurrently on the same cadence
+	runErrs := make([]error, len(sessions))
+	var wg sync.WaitGroup
+	for i, s := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if cfg.UntilFull {
+				_, err = s.monitor.RunUntilFull(ctx, cfg.MaxDuration, s.onSnapshot(header, recorder, notifier))
+			} else {
+				_, err = s.monitor.Run(ctx, s.onSnapshot(header, recorder, notifier))
+			}
+			if err != nil {
+				runErrs[i] = &addressError{address: s.address, err: err}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Wait for completion or interrupt
+	var runErr error
+	interrupted := false
+	select {
+	case <-sigChan:
+		interrupted = true
+		output.PrintInterrupted()
+		// The monitors return as soon as the context is cancelled, aborting
+		// the request in flight. Waiting for them means no callback still
+		// writes to the stream while the reports are saved.
+		cancel()
+		<-done
+		runErr = ctx.Err()
+	case <-done:
+		runErr = errors.Join(runErrs...)
+	}
+	// From here on Ctrl+C terminates the process as usual, e.g. when
+	// saving the reports hangs
+	signal.Stop(sigChan)
+
+	endTime := time.Now()
+	header(tronres.Snapshot{Failed: true}) // when no poll returned at all
+
+	// Even if interrupted, save what we have
+	var prices *tronres.ResourcePrices
+	thresholdsMet := true
+	var reports []tronres.MonitorReport
+	var noSamples []error
+	for i, s := range sessions {
+		snapshots := s.collected()
+		attempted, failed := s.monitor.PollCounts()
+		if resumed != nil {
+			// Reports of older versions don't count polls, assume none failed
+			previous := resumed.Metadata.AttemptedSamples
+			if previous == 0 {
+				previous = len(resumed.Snapshots) + resumed.Metadata.FailedSamples
+			}
+			attempted += previous
+			failed += resumed.Metadata.FailedSamples
+		}
+		if len(snapshots) == 0 {
+			// There is nothing to analyse or save. A run error or an
+			// interrupt already explains why, otherwise every poll failed.
+			if runErrs[i] == nil && !interrupted {
+				noSamples = append(noSamples, &addressError{
+					address: s.address,
+					err:     fmt.Errorf("%w: all %d polls failed, check the node and the address", errNoSamples, failed),
+				})
+			}
+			continue
+		}
+
+		// Prices are the same for every address, fetch them once
+		if prices == nil {
+			p := resourcePrices(c, cfg.EnergyFee)
+			prices = &p
+		}
+		analysis := analyzeSnapshots(cfg, snapshots, s.account, cfg.IntervalMs, attempted, *prices)
+
+		// Build and save report - use actual duration from analysis
+		actualDurationInt := int(analysis.ActualDurationSec)
+		if actualDurationInt < 1 {
+			actualDurationInt = 1
+		}
+		report := output.BuildReport(s.address, cfg.Nodes[0], startTime, endTime, actualDurationInt, snapshots, analysis)
+		report.Metadata.IntervalMs = cfg.IntervalMs
+		report.Metadata.ActualIntervalMeanMs, report.Metadata.ActualIntervalStddevMs = tronres.IntervalStats(snapshots)
+		report.Metadata.ClockAnomalies = tronres.ClockAnomalies(snapshots)
+		report.Metadata.NodesUsed = c.NodesUsed()
+		report.Metadata.Endpoint = c.ResourceEndpoint()
+		if resumed != nil {
+			report.Metadata.NodesUsed = mergeNodes(resumed.Metadata.NodesUsed, report.Metadata.NodesUsed)
+		}
+		report.Account = s.account
+		report.Metadata.AttemptedSamples = attempted
+		report.Metadata.FailedSamples = failed
+		throttled, wait := c.Throttled()
+		report.Metadata.ThrottledRequests = throttled
+		report.Metadata.ThrottleWaitMs = wait.Milliseconds()
+
+		filenames := saveReport(report, cfg, dest, s.stream)
+		if cfg.InfluxURL != "" {
+			// Not ctx, an interrupted run still sends what it collected
+			if err := output.PostInflux(context.Background(), cfg.InfluxURL, cfg.InfluxToken, cfg.Proxy, report, cfg.Influx); err != nil {
+				fmt.Fprintf(os.Stderr, "\nWarning: failed to write to InfluxDB: %v\n", err)
+			}
+		}
+		reports = append(reports, report)
+		title := ""
+		if len(sessions) > 1 {
+			title = s.address
+		}
+		if !printResults(cfg, title, report, *prices, filenames, filenames) {
+			thresholdsMet = false
+		}
+	}
+
+	// Throttling stretches the sample spacing, the reports show by how much
+	if throttled, wait := c.Throttled(); throttled > 0 {
+		fmt.Fprintf(os.Stderr, "\nWarning: --max-rps delayed %d requests by %s in total, see actual_interval_mean_ms in the report\n",
+			throttled, wait.Round(time.Millisecond))
+	}
+
+	// With several addresses stdout gets one JSON document per report
+	if cfg.JSONStdout {
+		for _, report := range reports {
+			if err := output.WriteJSON(os.Stdout, report); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(noSamples) > 0 {
+		runErr = errors.Join(append([]error{runErr}, noSamples...)...)
+	}
+	if runErr == nil && !thresholdsMet {
+		return errThresholdsNotMet
+	}
+	return runErr
+}
+
+// newClient creates the node client for the connection flags
+func newClient(cfg models.Config) *tronres.Client {
+	return tronres.NewClientWithOptions(cfg.Nodes[0], tronres.ClientOptions{
+		APIKey:         cfg.APIKey,
+		Timeout:        cfg.Timeout,
+		MaxRetries:     cfg.Retries,
+		InitialBackoff: cfg.Backoff,
+		FallbackNodes:  cfg.Nodes[1:],
+		Proxy:          cfg.Proxy,
+		Solidity:       cfg.Solidity,
+		MaxRPS:         cfg.MaxRPS,
+	})
+}
+
+// analyzeSnapshots analyses the snapshots of one address, taken by
+// attempted polls (0 = unknown). account, if not nil, splits the limits
+// into own stake and delegated-in resources.
+func analyzeSnapshots(cfg models.Config, snapshots []tronres.Snapshot, account *tronres.AccountInfo, intervalMs, attempted int, prices tronres.ResourcePrices) tronres.Analysis {
+	analysis := tronres.AnalyzeWithOptions(snapshots, tronres.AnalyzeOptions{
+		Prices:           prices,
+		IntervalMs:       intervalMs,
+		AttemptedSamples: attempted,
+		FilterOutliers:   cfg.FilterOutliers,
+		Window:           cfg.Window,
+	})
+	if account != nil {
+		breakdown := account.LimitBreakdown(snapshots[len(snapshots)-1])
+		analysis.ResourceBreakdown = &breakdown
+	}
+	return analysis
+}
+
+// printResults prints the summary of a report followed by the graph,
+// simulation, forecast and comparison that were asked for. filenames are
+// the files the report was saved to, exclude the files --compare skips.
+// It returns false when an alert threshold is not met.
+func printResults(cfg models.Config, title string, report tronres.MonitorReport, prices tronres.ResourcePrices, filenames, exclude []string) bool {
+	analysis := report.Analysis
+	output.PrintSummary(title, analysis, filenames...)
+	if cfg.Graph {
+		output.PrintGraph(report.Snapshots)
+	}
+
+	// Run simulation if requested
+	last := report.Snapshots[len(report.Snapshots)-1]
+	if cfg.Simulate {
+		opts := tronres.SimulateOptions{
+			TxCost:        cfg.TxCost,
+			TxMix:         cfg.TxMix,
+			BandwidthCost: cfg.BWCost,
+			TargetTx:      cfg.TargetTx,
+			Prices:        prices,
+		}
+		if b := analysis.ResourceBreakdown; b != nil {
+			opts.DelegatedInEnergy = b.EnergyDelegatedIn
+		}
+		output.PrintSimulation(tronres.Simulate(last, analysis, opts))
+	}
+
+	if cfg.ForecastTx > 0 {
+		target := int64(cfg.ForecastTx) * cfg.TxCost
+		output.PrintForecast(tronres.ForecastEnergy(last, analysis, target), cfg.ForecastTx)
+	}
+
+	// Compare with previous runs if requested
+	if len(cfg.CompareFiles) > 0 {
+		if err := compareWithPrevious(cfg.CompareFiles, report.Metadata.Address, analysis, exclude); err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: failed to compare: %v\n", err)
+		}
+	}
+
+	// Checked last, so the report is on disk whatever the outcome
+	failures := checkThresholds(cfg, analysis)
+	for _, failure := range failures {
+		output.PrintThresholdFailure(title, failure)
+	}
+	return len(failures) == 0
+}
+
+// checkThresholds returns a description of every alert threshold the analysis fails
+func checkThresholds(cfg models.Config, analysis tronres.Analysis) []string {
+	var failures []string
+
+	if cfg.MinTxPerDay > 0 {
+		if txPerDay := sustainedTxPerDay(analysis, cfg.TxCost, cfg.BWCost); txPerDay < cfg.MinTxPerDay {
+			failures = append(failures, fmt.Sprintf("sustained %.0f tx/day is below --min-tx-per-day %.0f", txPerDay, cfg.MinTxPerDay))
+		}
+	}
+
+	if cfg.MinEnergy > 0 && analysis.EnergyEnd < cfg.MinEnergy {
+		failures = append(failures, fmt.Sprintf("available energy %d is below --min-energy %d", analysis.EnergyEnd, cfg.MinEnergy))
+	}
+
+	return failures
+}
+
+// sustainedTxPerDay is the number of transactions per day the regeneration
+// alone pays for, limited by whichever resource runs out first
+func sustainedTxPerDay(analysis tronres.Analysis, txCost, bwCost int64) float64 {
+	txPerDay := math.Inf(1)
+	if txCost > 0 {
+		txPerDay = analysis.EnergyRegenRatePerDay / float64(txCost)
+	}
+	if bwCost > 0 {
+		txPerDay = math.Min(txPerDay, analysis.BandwidthRegenRatePerDay/float64(bwCost))
+	}
+	return txPerDay
+}
+
+// mergeNodes appends the nodes of b missing from a, keeping the order of first use
+func mergeNodes(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	for _, node := range b {
+		if !slices.Contains(merged, node) {
+			merged = append(merged, node)
+		}
+	}
+	return merged
+}
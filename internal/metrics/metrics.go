@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+const shutdownTimeout = 2 * time.Second
+
+// gauge describes an exported metric
+type gauge struct {
+	name string
+	help string
+}
+
+var gauges = []gauge{
+	{"tron_energy_available", "Energy currently available to the account"},
+	{"tron_energy_limit", "Energy limit of the account"},
+	{"tron_bandwidth_available", "Bandwidth (staked + free) currently available to the account"},
+	{"tron_bandwidth_limit", "Bandwidth limit (staked + free) of the account"},
+	{"tron_energy_regen_rate_per_sec", "Energy regenerated per second since monitoring started"},
+	{"tron_bandwidth_regen_rate_per_sec", "Bandwidth regenerated per second since monitoring started"},
+}
+
+// addressState holds the latest values for one address
+type addressState struct {
+	firstElapsedMs       int64
+	excludedMs           int64 // time spanned by deltas the analysis leaves out
+	energyRegenerated    int64
+	bandwidthRegenerated int64
+	previous             tronres.Snapshot
+	values               map[string]float64
+}
+
+// Recorder keeps the latest resource gauges per address and serves them
+// in the Prometheus text exposition format
+type Recorder struct {
+	mu        sync.Mutex
+	addresses map[string]*addressState
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{addresses: make(map[string]*addressState)}
+}
+
+// Observe updates the gauges of address from a snapshot
+func (r *Recorder) Observe(address string, snapshot tronres.Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.addresses[address]
+	if !ok {
+		state = &addressState{firstElapsedMs: snapshot.ElapsedMs, values: make(map[string]float64)}
+		r.addresses[address] = state
+	}
+
+	// Same deltas and time as the analysis, so the rates agree with the report
+	switch {
+	case !ok:
+		// the first snapshot has no delta
+	case tronres.ExcludedDelta(state.previous, snapshot):
+		state.excludedMs += snapshot.ElapsedMs - state.previous.ElapsedMs
+	default:
+		if snapshot.DeltaEnergy > 0 {
+			state.energyRegenerated += snapshot.DeltaEnergy
+		}
+		if snapshot.DeltaBandwidth > 0 {
+			state.bandwidthRegenerated += snapshot.DeltaBandwidth
+		}
+	}
+	state.previous = snapshot
+
+	state.values["tron_energy_available"] = float64(snapshot.EnergyAvailable)
+	state.values["tron_energy_limit"] = float64(snapshot.EnergyLimit)
+	state.values["tron_bandwidth_available"] = float64(snapshot.BandwidthAvailable)
+	state.values["tron_bandwidth_limit"] = float64(snapshot.TotalBandwidthLimit())
+
+	if elapsedSec := float64(snapshot.ElapsedMs-state.firstElapsedMs-state.excludedMs) / 1000.0; elapsedSec > 0 {
+		state.values["tron_energy_regen_rate_per_sec"] = float64(state.energyRegenerated) / elapsedSec
+		state.values["tron_bandwidth_regen_rate_per_sec"] = float64(state.bandwidthRegenerated) / elapsedSec
+	}
+}
+
+// ServeHTTP writes all gauges in the Prometheus text format
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addresses := make([]string, 0, len(r.addresses))
+	for address := range r.addresses {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var b strings.Builder
+	for _, g := range gauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n", g.name, g.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", g.name)
+		for _, address := range addresses {
+			if v, ok := r.addresses[address].values[g.name]; ok {
+				fmt.Fprintf(&b, "%s{address=%q} %g\n", g.name, address, v)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// Serve exposes the recorder on addr at /metrics until ctx is canceled.
+// It returns once the listener is open; the returned channel is closed
+// after the server has shut down.
+func Serve(ctx context.Context, addr string, r *Recorder) (<-chan struct{}, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Warning: metrics server stopped: %v\n", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	return done, nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/internal/output"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// Config holds CLI configuration
+type Config struct {
+	Addresses         []string
+	Nodes             []string
+	APIKey            string
+	Timeout           time.Duration
+	Retries           int
+	Backoff           time.Duration
+	Proxy             *url.URL
+	Solidity          bool
+	MaxRPS            float64
+	Duration          int
+	IntervalMs        int
+	UntilFull         bool
+	Once              bool
+	MaxDuration       int
+	RecoveryTarget    tronres.RecoveryTarget
+	CompareFiles      []string
+	FilterOutliers    bool
+	Window            time.Duration
+	Resume            string
+	Replay            string
+	Simulate          bool
+	TxCost            int64 // blended average when TxMix is set
+	TxMix             []tronres.TxCostShare
+	BWCost            int64
+	TargetTx          int
+	ForecastTx        int
+	EnergyFee         int64
+	MinTxPerDay       float64
+	MinEnergy         int64
+	MetricsAddr       string
+	WebhookURL        string
+	WebhookEnergy     int64
+	WebhookBandwidth  int64
+	InfluxURL         string
+	InfluxToken       string
+	Influx            output.InfluxOptions
+	Stream            bool
+	Format            string
+	Graph             bool
+	Quiet             bool
+	JSONStdout        bool
+	Location          *time.Location
+	TimezoneFilenames bool
+	NumberFormat      string
+	OutDir            string
+	OutFile           string
+}
@@ -2,30 +2,162 @@ package output
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
 )
 
-// PrintHeader prints the monitoring session header
-func PrintHeader(address, node string, duration int, intervalMs int, startTime time.Time) {
-	fmt.Println("TRON Resource Monitor")
-	fmt.Printf("Address: %s\n", address)
-	fmt.Printf("Node: %s\n", node)
-	fmt.Printf("Duration: %d seconds (interval: %dms)\n", duration, intervalMs)
-	fmt.Printf("Started: %s\n", startTime.UTC().Format("2006-01-02 15:04:05 UTC"))
-	fmt.Println(strings.Repeat("=", 100))
-	fmt.Println()
+// console is where the Print functions write, see Configure
+var (
+	console      io.Writer = os.Stdout
+	quiet        bool
+	location     = time.UTC
+	thousandsSep = ","
+)
+
+// Number formats accepted by ConsoleOptions.NumberFormat
+const (
+	NumberComma      = "comma"
+	NumberSpace      = "space"
+	NumberUnderscore = "underscore"
+	NumberNone       = "none"
+)
+
+var numberSeparators = map[string]string{
+	NumberComma:      ",",
+	NumberSpace:      " ",
+	NumberUnderscore: "_",
+	NumberNone:       "",
+}
+
+// NumberFormats returns the names accepted by ConsoleOptions.NumberFormat
+func NumberFormats() []string {
+	return []string{NumberComma, NumberSpace, NumberUnderscore, NumberNone}
+}
+
+// ConsoleOptions controls the Print functions
+type ConsoleOptions struct {
+	// Quiet skips the header and the per-snapshot lines, the summary is still printed
+	Quiet bool
+	// Stderr sends all human-readable output to stderr, keeping stdout
+	// free for machine-readable output such as WriteJSON
+	Stderr bool
+	// Location is the time zone timestamps are shown in (default UTC)
+	Location *time.Location
+	// NumberFormat is the thousands separator of printed numbers, one of
+	// NumberFormats (default NumberComma). Unknown names fall back to the default.
+	NumberFormat string
+}
+
+// Configure sets up the Print functions. It must be called before monitoring starts.
+func Configure(opts ConsoleOptions) {
+	quiet = opts.Quiet
+	location = time.UTC
+	if opts.Location != nil {
+		location = opts.Location
+	}
+	thousandsSep = numberSeparators[NumberComma]
+	if sep, ok := numberSeparators[opts.NumberFormat]; ok {
+		thousandsSep = sep
+	}
+	console = os.Stdout
+	if opts.Stderr {
+		console = os.Stderr
+	}
+}
+
+// HeaderAddress is a monitored address shown in the session header.
+// Account may be nil when the account info could not be fetched.
+type HeaderAddress struct {
+	Address string
+	Account *tronres.AccountInfo
 }
 
-// PrintSnapshot prints a single snapshot line
-func PrintSnapshot(snapshot models.ResourceSnapshot, index int) {
+// PrintHeader prints the monitoring session header. network, if not nil,
+// is a snapshot the network totals are taken from.
+func PrintHeader(addresses []HeaderAddress, network *tronres.Snapshot, node string, duration int, intervalMs int, startTime time.Time) {
+	if quiet {
+		return
+	}
+	fmt.Fprintln(console, "TRON Resource Monitor")
+	if len(addresses) == 1 {
+		fmt.Fprintf(console, "Address: %s\n", addresses[0].Address)
+	} else {
+		fmt.Fprintln(console, "Addresses:")
+		for _, a := range addresses {
+			fmt.Fprintf(console, "  [%s] %s\n", ShortAddress(a.Address), a.Address)
+		}
+	}
+	fmt.Fprintf(console, "Node: %s\n", node)
+	for _, a := range addresses {
+		if a.Account == nil {
+			continue
+		}
+		prefix := ""
+		if len(addresses) > 1 {
+			prefix = "[" + ShortAddress(a.Address) + "] "
+		}
+		fmt.Fprintf(console, "%sStaked: %s TRX for energy, %s TRX for bandwidth, balance %s TRX\n",
+			prefix,
+			formatTRX(a.Account.TotalStakedEnergySun()),
+			formatTRX(a.Account.TotalStakedBandwidthSun()),
+			formatTRX(a.Account.BalanceSun),
+		)
+	}
+	if network != nil && network.TotalEnergyWeight > 0 && network.TotalNetWeight > 0 {
+		fmt.Fprintf(console, "Network energy: %s for %s TRX staked (%.2f per TRX)\n",
+			formatNumber(network.TotalEnergyLimit),
+			formatNumber(network.TotalEnergyWeight),
+			float64(network.TotalEnergyLimit)/float64(network.TotalEnergyWeight),
+		)
+		fmt.Fprintf(console, "Network bandwidth: %s for %s TRX staked (%.2f per TRX)\n",
+			formatNumber(network.TotalNetLimit),
+			formatNumber(network.TotalNetWeight),
+			float64(network.TotalNetLimit)/float64(network.TotalNetWeight),
+		)
+	}
+	fmt.Fprintf(console, "Duration: %d seconds (interval: %dms)\n", duration, intervalMs)
+	fmt.Fprintf(console, "Started: %s\n", formatTime(startTime))
+	fmt.Fprintln(console, strings.Repeat("=", 100))
+	fmt.Fprintln(console)
+}
+
+// ShortAddress shortens an address to its first and last 4 characters
+func ShortAddress(address string) string {
+	if len(address) > 8 {
+		return address[:4] + "..." + address[len(address)-4:]
+	}
+	return address
+}
+
+// PrintSnapshot prints a single snapshot line. A non-empty tag is printed
+// in front of the line so output of several addresses can be told apart.
+// The line is written with a single call to keep concurrent output intact.
+func PrintSnapshot(snapshot tronres.Snapshot, index int, tag string) {
+	if quiet {
+		return
+	}
 	// Use actual elapsed time from snapshot
 	elapsedSec := float64(snapshot.ElapsedMs) / 1000.0
 
+	prefix := ""
+	if tag != "" {
+		prefix = "[" + tag + "] "
+	}
+
+	if snapshot.Failed {
+		fmt.Fprintf(console, "%s[T+%05.1fs] poll failed, no data\n", prefix, elapsedSec)
+		return
+	}
+
 	if index == 0 {
-		fmt.Printf("[T+%05.1fs] Energy: %s / %s (avail: %s) | BW: %s / %s (avail: %s)\n",
+		fmt.Fprintf(console, "%s[T+%05.1fs] Energy: %s / %s (avail: %s) | BW: %s / %s (avail: %s)%s\n",
+			prefix,
 			elapsedSec,
 			formatNumber(snapshot.EnergyAvailable),
 			formatNumber(snapshot.EnergyLimit),
@@ -33,9 +165,11 @@ func PrintSnapshot(snapshot models.ResourceSnapshot, index int) {
 			formatNumber(snapshot.BandwidthAvailable),
 			formatNumber(snapshot.TotalBandwidthLimit()),
 			formatNumber(snapshot.BandwidthAvailable),
+			formatRecovery(snapshot.Recovery),
 		)
 	} else {
-		fmt.Printf("[T+%05.1fs] Energy: %s / %s (avail: %s) | BW: %s / %s (avail: %s) | ΔE: %s | ΔBW: %s\n",
+		fmt.Fprintf(console, "%s[T+%05.1fs] Energy: %s / %s (avail: %s) | BW: %s / %s (avail: %s) | ΔE: %s | ΔBW: %s%s%s\n",
+			prefix,
 			elapsedSec,
 			formatNumber(snapshot.EnergyAvailable),
 			formatNumber(snapshot.EnergyLimit),
@@ -45,77 +179,153 @@ func PrintSnapshot(snapshot models.ResourceSnapshot, index int) {
 			formatNumber(snapshot.BandwidthAvailable),
 			formatDelta(snapshot.DeltaEnergy),
 			formatDelta(snapshot.DeltaBandwidth),
+			formatClockSkew(snapshot),
+			formatRecovery(snapshot.Recovery),
 		)
 	}
 }
 
-// PrintSummary prints the analysis summary
-func PrintSummary(analysis models.Analysis, filename string) {
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 100))
-	fmt.Printf("SUMMARY (%.1f seconds):\n", analysis.ActualDurationSec)
+// formatClockSkew flags a snapshot taken across a jump of the system clock
+func formatClockSkew(snapshot tronres.Snapshot) string {
+	if !snapshot.ClockAnomaly {
+		return ""
+	}
+	return fmt.Sprintf(" | clock jumped %+.1fs, delta excluded", float64(snapshot.ClockSkewMs)/1000)
+}
+
+// formatRecovery formats the recovery progress appended to snapshot lines in --until-full mode
+func formatRecovery(p *tronres.RecoveryProgress) string {
+	if p == nil {
+		return ""
+	}
+	eta := "ETA: unknown"
+	if p.ETA >= 0 {
+		eta = "ETA: " + p.ETA.Round(time.Second).String()
+	}
+	return fmt.Sprintf(" | recovered: E %.1f%% BW %.1f%% | %s", p.EnergyPercent, p.BandwidthPercent, eta)
+}
+
+// PrintSummary prints the analysis summary followed by the saved file paths.
+// A non-empty address is named in the title, for runs with several addresses.
+func PrintSummary(address string, analysis tronres.Analysis, filenames ...string) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, strings.Repeat("=", 100))
+	if address != "" {
+		fmt.Fprintf(console, "SUMMARY for %s (%.1f seconds):\n", address, analysis.ActualDurationSec)
+	} else {
+		fmt.Fprintf(console, "SUMMARY (%.1f seconds):\n", analysis.ActualDurationSec)
+	}
+	if analysis.SampleSuccessRate > 0 {
+		fmt.Fprintf(console, "  Sample success rate: %.1f%%\n", analysis.SampleSuccessRate*100)
+	}
 
 	// Separated rates
-	fmt.Println()
-	fmt.Println("  Energy Rates:")
-	fmt.Printf("    Regeneration: %s /sec  (%s /day)\n",
-		formatFloat(analysis.EnergyRegenRatePerSec),
-		formatNumber(int64(analysis.EnergyRegenRatePerDay)),
-	)
-	fmt.Printf("    Consumption:  %s /sec  (%s /day)\n",
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Energy Rates:")
+	reg := analysis.RegressionAnalysis
+	if reg.Samples > 0 {
+		fmt.Fprintf(console, "    Regeneration: %s /sec  (%s /day)  [regression: %s ± %s /sec, R² %.3f]\n",
+			formatFloat(analysis.EnergyRegenRatePerSec),
+			formatNumber(int64(analysis.EnergyRegenRatePerDay)),
+			formatFloat(reg.SlopePerSec),
+			formatFloat(reg.StdError),
+			reg.RSquared,
+		)
+	} else {
+		fmt.Fprintf(console, "    Regeneration: %s /sec  (%s /day)\n",
+			formatFloat(analysis.EnergyRegenRatePerSec),
+			formatNumber(int64(analysis.EnergyRegenRatePerDay)),
+		)
+	}
+	fmt.Fprintf(console, "    Consumption:  %s /sec  (%s /day)\n",
 		formatFloat(analysis.EnergyConsumeRatePerSec),
 		formatNumber(int64(analysis.EnergyConsumeRatePerDay)),
 	)
-	fmt.Printf("    Net:          %s /sec  (%s /day)\n",
+	fmt.Fprintf(console, "    Net:          %s /sec  (%s /day)\n",
 		formatFloat(analysis.EnergyNetRatePerSec),
 		formatDelta(int64(analysis.EnergyNetRatePerDay)),
 	)
 
-	fmt.Println()
-	fmt.Println("  Bandwidth Rates:")
-	fmt.Printf("    Regeneration: %s /sec  (%s /day)\n",
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Bandwidth Rates:")
+	fmt.Fprintf(console, "    Regeneration: %s /sec  (%s /day)\n",
 		formatFloat(analysis.BandwidthRegenRatePerSec),
 		formatNumber(int64(analysis.BandwidthRegenRatePerDay)),
 	)
-	fmt.Printf("    Consumption:  %s /sec  (%s /day)\n",
+	fmt.Fprintf(console, "    Consumption:  %s /sec  (%s /day)\n",
 		formatFloat(analysis.BandwidthConsumeRatePerSec),
 		formatNumber(int64(analysis.BandwidthConsumeRatePerDay)),
 	)
-	fmt.Printf("    Net:          %s /sec  (%s /day)\n",
+	fmt.Fprintf(console, "    Net:          %s /sec  (%s /day)\n",
 		formatFloat(analysis.BandwidthNetRatePerSec),
 		formatDelta(int64(analysis.BandwidthNetRatePerDay)),
 	)
+	fmt.Fprintf(console, "    By source:    staked %s /day regen, free %s /day regen\n",
+		formatNumber(int64(analysis.StakedBandwidthRegenRatePerDay)),
+		formatNumber(int64(analysis.FreeBandwidthRegenRatePerDay)),
+	)
 
 	// Resource totals
-	fmt.Println()
-	fmt.Println("  Resource Totals:")
-	fmt.Printf("    Energy:    regenerated %s, consumed %s, net %s\n",
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Resource Totals:")
+	fmt.Fprintf(console, "    Energy:    regenerated %s, consumed %s, net %s\n",
 		formatNumber(analysis.EnergyRegenerated),
 		formatNumber(analysis.EnergyConsumed),
 		formatDelta(analysis.EnergyTotalDelta),
 	)
-	fmt.Printf("    Bandwidth: regenerated %s, consumed %s, net %s\n",
+	fmt.Fprintf(console, "    Bandwidth: regenerated %s, consumed %s, net %s\n",
 		formatNumber(analysis.BandwidthRegenerated),
 		formatNumber(analysis.BandwidthConsumed),
 		formatDelta(analysis.BandwidthTotalDelta),
 	)
 
+	// Range traversed, to tell a mid-run spike from a steady drain
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Available Range:")
+	printRange("Energy:   ", analysis.EnergyAvailableStats)
+	printRange("Bandwidth:", analysis.BandwidthAvailableStats)
+
+	// Delegated-in resources can be taken back by the delegator at any time
+	if b := analysis.ResourceBreakdown; b != nil && (b.EnergyDelegatedIn > 0 || b.BandwidthDelegatedIn > 0) {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Limit Breakdown:")
+		if b.EnergyDelegatedIn > 0 {
+			fmt.Fprintf(console, "    Energy:    own stake %s, delegated-in energy: %s (reclaimable)\n",
+				formatNumber(b.EnergyFromStake), formatNumber(b.EnergyDelegatedIn))
+		}
+		if b.BandwidthDelegatedIn > 0 {
+			fmt.Fprintf(console, "    Bandwidth: own stake %s, delegated-in bandwidth: %s (reclaimable)\n",
+				formatNumber(b.BandwidthFromStake), formatNumber(b.BandwidthDelegatedIn))
+		}
+	}
+
 	// Tick analysis
 	tick := analysis.TickAnalysis
 	if tick.RecoveryTicks > 0 || tick.ConsumptionEvents > 0 {
-		fmt.Println()
-		fmt.Println("  Block Tick Analysis:")
-		fmt.Printf("    Recovery ticks: %d (avg interval: %.1f sec, ~%.0f/day)\n",
-			tick.RecoveryTicks, tick.AvgRecoveryInterval, tick.RecoveryTicksPerDay)
-		fmt.Printf("    Avg energy/tick: %s, bandwidth/tick: %.1f\n",
-			formatNumber(int64(tick.EnergyPerTick)), tick.BandwidthPerTick)
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Block Tick Analysis:")
+		fmt.Fprintf(console, "    Recovery ticks: %d (avg interval: %.1f sec, median interval: %.1f sec, ~%.0f/day)\n",
+			tick.RecoveryTicks, tick.AvgRecoveryInterval, tick.MedianRecoveryInterval, tick.RecoveryTicksPerDay)
+		if tick.RecoveryTicks > 1 {
+			fmt.Fprintf(console, "    Interval p90/p95: %.1f / %.1f sec, stddev %.1f sec (CV %.0f%%)\n",
+				tick.P90RecoveryInterval, tick.P95RecoveryInterval,
+				tick.RecoveryIntervalStddev, tick.RecoveryIntervalCV*100)
+		}
+		fmt.Fprintf(console, "    Energy/tick: %s ± %s (CV %.0f%%), median %s, p90 %s, p95 %s, bandwidth/tick: %.1f\n",
+			formatNumber(int64(tick.EnergyPerTick)),
+			formatNumber(int64(math.Round(tick.EnergyPerTickStddev))),
+			tick.EnergyPerTickCV*100,
+			formatNumber(int64(tick.MedianEnergyPerTick)),
+			formatNumber(int64(tick.P90EnergyPerTick)),
+			formatNumber(int64(tick.P95EnergyPerTick)),
+			tick.BandwidthPerTick)
 
 		if tick.ConsumptionEvents > 0 {
-			fmt.Printf("    Consumption events: %d (total: %s energy, %s bandwidth)\n",
+			fmt.Fprintf(console, "    Consumption events: %d (total: %s energy, %s bandwidth)\n",
 				tick.ConsumptionEvents,
 				formatNumber(tick.TotalEnergyConsumed),
 				formatNumber(tick.TotalBandwidthConsumed))
-			fmt.Printf("    Avg per consumption: %s energy, %.0f bandwidth\n",
+			fmt.Fprintf(console, "    Avg per consumption: %s energy, %.0f bandwidth\n",
 				formatNumber(int64(tick.AvgEnergyPerConsume)),
 				tick.AvgBandwidthPerConsume)
 		}
@@ -124,37 +334,37 @@ func PrintSummary(analysis models.Analysis, filename string) {
 	// Used-based analysis
 	used := analysis.UsedBasedAnalysis
 	if used.EnergyUsedAtStart > 0 {
-		fmt.Println()
-		fmt.Println("  Recovery Analysis:")
-		fmt.Printf("    Energy used ratio: %.1f%% (%s / %s)\n",
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Recovery Analysis:")
+		fmt.Fprintf(console, "    Energy used ratio: %.1f%% (%s / %s)\n",
 			used.EnergyUsedRatio*100,
 			formatNumber(used.EnergyUsedAtStart),
 			formatNumber(analysis.EnergyStart+used.EnergyUsedAtStart))
-		fmt.Printf("    Bandwidth used ratio: %.1f%%\n", used.BandwidthUsedRatio*100)
+		fmt.Fprintf(console, "    Bandwidth used ratio: %.1f%%\n", used.BandwidthUsedRatio*100)
 		if used.EstimatedFullRecoveryHours > 0 {
-			fmt.Printf("    Estimated full recovery: %.1f hours\n", used.EstimatedFullRecoveryHours)
+			fmt.Fprintf(console, "    Estimated full recovery: %.1f hours\n", used.EstimatedFullRecoveryHours)
 		}
-		fmt.Printf("    Measured regen: %.1f/sec, Theoretical (used-based): %.1f/sec\n",
+		fmt.Fprintf(console, "    Measured regen: %.1f/sec, Theoretical (used-based): %.1f/sec\n",
 			used.MeasuredRecoveryRate, used.UsedBasedRecoveryRate)
 
 		matchStr := "NO"
 		if used.EnergyRecoveryMatchesUsedModel {
 			matchStr = "YES"
 		}
-		fmt.Printf("    Matches used-based model: %s\n", matchStr)
+		fmt.Fprintf(console, "    Matches used-based model: %s\n", matchStr)
 	}
 
 	// Formula validation
 	fv := analysis.FormulaValidation
 	if fv.BestFit != "" {
-		fmt.Println()
-		fmt.Println("  Formula Validation:")
-		fmt.Printf("    Best fit model: %s (confidence: %.1f%%)\n", fv.BestFit, fv.Confidence*100)
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Formula Validation:")
+		fmt.Fprintf(console, "    Best fit model: %s (confidence: %.1f%%)\n", fv.BestFit, fv.Confidence*100)
 	}
 
 	// Theoretical comparison
-	fmt.Println()
-	fmt.Println("  Theoretical vs Measured (Regen Rate):")
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Theoretical vs Measured (Regen Rate):")
 	energyMatch := "NO"
 	if analysis.EnergyRateMatchesTheory {
 		energyMatch = "YES"
@@ -163,36 +373,114 @@ func PrintSummary(analysis models.Analysis, filename string) {
 	if analysis.BandwidthRateMatchesTheory {
 		bwMatch = "YES"
 	}
-	fmt.Printf("    Energy:    theoretical %s/day, measured %s/day, match: %s\n",
+	fmt.Fprintf(console, "    Energy:    theoretical %s/day, measured %s/day, match: %s\n",
 		formatNumber(int64(analysis.TheoreticalEnergyRatePerDay)),
 		formatNumber(int64(analysis.EnergyRegenRatePerDay)),
 		energyMatch,
 	)
-	fmt.Printf("    Bandwidth: theoretical %s/day, measured %s/day, match: %s\n",
+	fmt.Fprintf(console, "    Bandwidth: theoretical %s/day, measured %s/day, match: %s\n",
 		formatNumber(int64(analysis.TheoreticalBandwidthRatePerDay)),
 		formatNumber(int64(analysis.BandwidthRegenRatePerDay)),
 		bwMatch,
 	)
 
+	// Network share
+	if analysis.EnergyPerStakedTRX > 0 || analysis.BandwidthPerStakedTRX > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Network Share (staked limits):")
+		fmt.Fprintf(console, "    Energy:    %s%% of the network, %.2f per staked TRX\n",
+			formatShare(analysis.EnergyShareRatio), analysis.EnergyPerStakedTRX)
+		fmt.Fprintf(console, "    Bandwidth: %s%% of the network, %.2f per staked TRX\n",
+			formatShare(analysis.BandwidthShareRatio), analysis.BandwidthPerStakedTRX)
+	}
+
 	// Practical estimates
 	est := analysis.PracticalEstimates
-	fmt.Println()
-	fmt.Println("  Transaction Capacity (based on regen rate):")
-	fmt.Printf("    Immediate (from buffer):\n")
-	fmt.Printf("      At 65k Energy/tx:  %d tx\n", est.ImmediateCapacity65k)
-	fmt.Printf("      At 131k Energy/tx: %d tx\n", est.ImmediateCapacity131k)
-	fmt.Printf("    Sustained (regen only):\n")
-	fmt.Printf("      At 65k Energy/tx:  %.0f tx/day\n", est.TxPerDay65kSustained)
-	fmt.Printf("      At 131k Energy/tx: %.0f tx/day\n", est.TxPerDay131kSustained)
-	fmt.Printf("    With buffer (immediate + regen):\n")
-	fmt.Printf("      At 65k Energy/tx:  %.0f tx/day\n", est.TxPerDay65kWithBuffer)
-	fmt.Printf("      At 131k Energy/tx: %.0f tx/day\n", est.TxPerDay131kWithBuffer)
-
-	fmt.Println()
-	fmt.Printf("Log saved to: %s\n", filename)
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Transaction Capacity (based on regen rate):")
+	fmt.Fprintf(console, "    Immediate (from buffer):\n")
+	fmt.Fprintf(console, "      At 65k Energy/tx:  %d tx\n", est.ImmediateCapacity65k)
+	fmt.Fprintf(console, "      At 131k Energy/tx: %d tx\n", est.ImmediateCapacity131k)
+	fmt.Fprintf(console, "    Sustained (regen only):\n")
+	fmt.Fprintf(console, "      At 65k Energy/tx:  %.0f tx/day\n", est.TxPerDay65kSustained)
+	fmt.Fprintf(console, "      At 131k Energy/tx: %.0f tx/day\n", est.TxPerDay131kSustained)
+	fmt.Fprintf(console, "    With buffer (immediate + regen):\n")
+	fmt.Fprintf(console, "      At 65k Energy/tx:  %.0f tx/day\n", est.TxPerDay65kWithBuffer)
+	fmt.Fprintf(console, "      At 131k Energy/tx: %.0f tx/day\n", est.TxPerDay131kWithBuffer)
+	fmt.Fprintf(console, "    TRX transfers (%d bandwidth/tx):\n", tronres.TransferBandwidthCost)
+	fmt.Fprintf(console, "      Staked bandwidth:  %.0f tx/day\n", est.TxPerDayTransferStaked)
+	fmt.Fprintf(console, "      Free bandwidth:    %.0f tx/day\n", est.TxPerDayTransferFree)
+	fmt.Fprintf(console, "      Total:             %.0f tx/day\n", est.TxPerDayTransfer)
+
+	if est.TrxBurnedEstimate > 0 || est.TrxBurnedPerDayEstimate > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  TRX Burn Equivalent (if consumption had no resources):")
+		fmt.Fprintf(console, "    This session: %.2f TRX\n", est.TrxBurnedEstimate)
+		fmt.Fprintf(console, "    Per day:      %.2f TRX\n", est.TrxBurnedPerDayEstimate)
+	}
+
+	if len(analysis.RateWindows) > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Regeneration by Window:")
+		fmt.Fprintf(console, "    %-8s  %-8s  %8s  %12s  %14s\n", "Start", "End", "Samples", "Energy /sec", "Bandwidth /sec")
+		for _, w := range analysis.RateWindows {
+			fmt.Fprintf(console, "    %-8s  %-8s  %8d  %12s  %14s\n",
+				w.WindowStart.In(location).Format("15:04:05"),
+				w.WindowEnd.In(location).Format("15:04:05"),
+				w.Samples,
+				formatFloat(w.EnergyRegenRate),
+				formatFloat(w.BandwidthRegenRate),
+			)
+		}
+	}
+
+	if len(analysis.Warnings) > 0 || len(analysis.LimitChangeEvents) > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "  Warnings:")
+		for _, w := range analysis.Warnings {
+			fmt.Fprintf(console, "    ! %s\n", w)
+		}
+		for _, e := range analysis.LimitChangeEvents {
+			fmt.Fprintf(console, "    ! %s limit changed at T+%.1fs: %s -> %s (delta excluded from rates)\n",
+				e.Resource,
+				float64(e.ElapsedMs)/1000.0,
+				formatNumber(e.OldLimit),
+				formatNumber(e.NewLimit),
+			)
+		}
+	}
+
+	if len(filenames) > 0 {
+		fmt.Fprintln(console)
+	}
+	for _, filename := range filenames {
+		fmt.Fprintf(console, "Log saved to: %s\n", filename)
+	}
+}
+
+func printRange(label string, stats tronres.ResourceStats) {
+	fmt.Fprintf(console, "    %s min %s, max %s, mean %s, stddev %s\n",
+		label,
+		formatNumber(stats.Min),
+		formatNumber(stats.Max),
+		formatFloat(stats.Mean),
+		formatFloat(stats.Stddev),
+	)
 }
 
+// formatShare formats a ratio as a percentage with 4 significant digits,
+// without an exponent since an account's share of the network is tiny
+func formatShare(ratio float64) string {
+	rounded, _ := strconv.ParseFloat(fmt.Sprintf("%.4g", ratio*100), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// formatFloat formats large values as whole numbers with separators and
+// small ones with a decimal, negatives like their absolute value
 func formatFloat(f float64) string {
+	if f < 0 {
+		return "-" + formatFloat(-f)
+	}
 	if f >= 1000 {
 		return formatNumber(int64(f))
 	}
@@ -200,52 +488,351 @@ func formatFloat(f float64) string {
 }
 
 // PrintSimulation prints simulation results
-func PrintSimulation(sim models.SimulationResult) {
-	fmt.Println()
-	fmt.Println(strings.Repeat("━", 60))
-	fmt.Printf("Transaction Simulation (target: %d tx @ %s energy each)\n",
-		sim.TargetTx, formatNumber(sim.TxCost))
-	fmt.Println(strings.Repeat("━", 60))
-
-	fmt.Printf("Current available: %s energy\n", formatNumber(sim.CurrentAvailable))
-	fmt.Printf("Immediate capacity: %d tx\n", sim.ImmediateCapacity)
-	fmt.Println()
-
-	fmt.Printf("Recovery rate: %.1f energy/sec = 1 tx every %.1f sec\n",
-		sim.RecoveryRatePerSec, sim.SecondsPerTx)
-	fmt.Println()
-
-	fmt.Println("Projection for next 24 hours:")
+func PrintSimulation(sim tronres.SimulationResult) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, strings.Repeat("━", 60))
+	switch {
+	case len(sim.TxMix) > 0 && sim.Bandwidth != nil:
+		fmt.Fprintf(console, "Transaction Simulation (target: %d tx @ %s energy on average + %s bandwidth each)\n",
+			sim.TargetTx, formatNumber(sim.TxCost), formatNumber(sim.Bandwidth.TxCost))
+	case len(sim.TxMix) > 0:
+		fmt.Fprintf(console, "Transaction Simulation (target: %d tx @ %s energy on average)\n",
+			sim.TargetTx, formatNumber(sim.TxCost))
+	case sim.Bandwidth != nil && sim.TxCost > 0:
+		fmt.Fprintf(console, "Transaction Simulation (target: %d tx @ %s energy + %s bandwidth each)\n",
+			sim.TargetTx, formatNumber(sim.TxCost), formatNumber(sim.Bandwidth.TxCost))
+	case sim.Bandwidth != nil:
+		fmt.Fprintf(console, "Transaction Simulation (target: %d tx @ %s bandwidth each)\n",
+			sim.TargetTx, formatNumber(sim.Bandwidth.TxCost))
+	default:
+		fmt.Fprintf(console, "Transaction Simulation (target: %d tx @ %s energy each)\n",
+			sim.TargetTx, formatNumber(sim.TxCost))
+	}
+	fmt.Fprintln(console, strings.Repeat("━", 60))
+
+	if sim.TxCost > 0 {
+		if sim.Bandwidth != nil {
+			fmt.Fprintln(console, "Energy:")
+		}
+		fmt.Fprintf(console, "Current available: %s energy\n", formatNumber(sim.CurrentAvailable))
+		fmt.Fprintf(console, "Immediate capacity: %d tx\n", sim.ImmediateCapacity)
+		fmt.Fprintln(console)
+
+		fmt.Fprintf(console, "Recovery rate: %.1f energy/sec = 1 tx every %.1f sec\n",
+			sim.RecoveryRatePerSec, sim.SecondsPerTx)
+		fmt.Fprintln(console)
+
+		printProjection(sim.HourlyProjection)
+
+		fmt.Fprintf(console, "Total 24h: %d tx\n", sim.Total24hCapacity)
+		if sim.DelegatedInEnergy > 0 {
+			fmt.Fprintf(console, "Delegated-in energy: %s (reclaimable), without it: %d tx\n",
+				formatNumber(sim.DelegatedInEnergy), sim.OwnStake24hCapacity)
+		}
+		fmt.Fprintln(console)
+	}
+
+	if bw := sim.Bandwidth; bw != nil {
+		if sim.TxCost > 0 {
+			fmt.Fprintln(console, "Bandwidth:")
+		}
+		fmt.Fprintf(console, "Current available: %s bandwidth (staked %s, free %s)\n",
+			formatNumber(bw.CurrentAvailable), formatNumber(bw.StakedAvailable), formatNumber(bw.FreeAvailable))
+		fmt.Fprintf(console, "Immediate capacity: %d tx (staked %d, free %d)\n",
+			bw.ImmediateCapacity, bw.ImmediateFromStaked, bw.ImmediateFromFree)
+		fmt.Fprintln(console)
+
+		fmt.Fprintf(console, "Recovery rate: %.1f bandwidth/sec = 1 tx every %.1f sec\n",
+			bw.RecoveryRatePerSec, bw.SecondsPerTx)
+		fmt.Fprintf(console, "Sustained: ~%.0f tx/day (staked %.0f, free %.0f)\n",
+			bw.SustainedTxPerDay, bw.SustainedFromStaked, bw.SustainedFromFree)
+		fmt.Fprintln(console)
+
+		printProjection(bw.HourlyProjection)
+
+		fmt.Fprintf(console, "Total 24h: %d tx\n", bw.Total24hCapacity)
+		fmt.Fprintln(console)
+	}
+
+	if sim.Bandwidth != nil && sim.TxCost > 0 {
+		fmt.Fprintf(console, "Binding constraint: %s (%d tx/day)\n", sim.BindingConstraint, sim.EffectiveCapacity)
+		fmt.Fprintln(console)
+	}
+
+	if len(sim.TxMix) > 0 {
+		fmt.Fprintf(console, "Mix breakdown (%d tx/day):\n", sim.EffectiveCapacity)
+		for _, m := range sim.TxMix {
+			if m.Cost > 0 {
+				fmt.Fprintf(console, "  %3.0f%% @ %s energy: %d tx/day (alone: %d tx/day)\n",
+					m.Weight*100, formatNumber(m.Cost), m.Total24hCapacity, m.AloneCapacity)
+			} else {
+				fmt.Fprintf(console, "  %3.0f%% energy-free: %d tx/day\n", m.Weight*100, m.Total24hCapacity)
+			}
+		}
+		fmt.Fprintln(console)
+	}
+
+	if sim.CanReachTarget {
+		fmt.Fprintf(console, "✓ Can reach target of %d tx/day\n", sim.TargetTx)
+	} else {
+		fmt.Fprintf(console, "✗ Cannot reach %d tx/day with current resources\n", sim.TargetTx)
+		fmt.Fprintln(console)
+		if sim.RequiredEnergyLimit > 0 {
+			fmt.Fprintf(console, "Required energy_limit for %d tx/day: %s\n",
+				sim.TargetTx, formatNumber(sim.RequiredEnergyLimit))
+		}
+		if sim.Bandwidth != nil && sim.Bandwidth.RequiredBandwidthLimit > 0 {
+			fmt.Fprintf(console, "Required bandwidth limit for %d tx/day: %s\n",
+				sim.TargetTx, formatNumber(sim.Bandwidth.RequiredBandwidthLimit))
+		}
+		if sim.TrxBurnedEstimate > 0 {
+			fmt.Fprintf(console, "Or burn ~%.2f TRX/day to cover the shortfall (%s energy, %s bandwidth @ %d/%d sun)\n",
+				sim.TrxBurnedEstimate,
+				formatNumber(sim.EnergyShortfall),
+				formatNumber(sim.BandwidthShortfall),
+				sim.Prices.EnergyFeeSun,
+				sim.Prices.BandwidthFeeSun)
+		}
+	}
+}
+
+func printProjection(projection []int64) {
+	fmt.Fprintln(console, "Projection for next 24 hours:")
 	for hour := 0; hour < 24; hour++ {
 		if hour < 6 || hour >= 22 {
-			fmt.Printf("  Hour %2d: %4d tx\n", hour, sim.HourlyProjection[hour])
+			fmt.Fprintf(console, "  Hour %2d: %4d tx\n", hour, projection[hour])
 		} else if hour == 6 {
-			fmt.Println("  ...")
+			fmt.Fprintln(console, "  ...")
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(console)
+}
 
-	fmt.Printf("Total 24h: %d tx\n", sim.Total24hCapacity)
-	fmt.Println()
+// PrintForecast prints when the energy for txCount transactions will be available
+func PrintForecast(f tronres.Forecast, txCount int) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, strings.Repeat("━", 60))
+	fmt.Fprintf(console, "Energy Forecast (%d tx = %s energy)\n", txCount, formatNumber(f.TargetEnergy))
+	fmt.Fprintln(console, strings.Repeat("━", 60))
+
+	fmt.Fprintf(console, "Current available: %s / %s energy\n", formatNumber(f.CurrentEnergy), formatNumber(f.EnergyLimit))
+	fmt.Fprintf(console, "Net rate: %s energy/sec\n", formatFloat(f.NetRatePerSec))
+	if f.Capped {
+		fmt.Fprintf(console, "! Target exceeds the energy limit, forecasting full limit (%s) instead\n", formatNumber(f.ReachableTarget))
+	}
 
-	if sim.CanReachTarget {
-		fmt.Printf("✓ Can reach target of %d tx/day\n", sim.TargetTx)
-	} else {
-		fmt.Printf("✗ Cannot reach %d tx/day with current resources\n", sim.TargetTx)
-		fmt.Println()
-		fmt.Printf("Required energy_limit for %d tx/day: %s\n",
-			sim.TargetTx, formatNumber(sim.RequiredEnergyLimit))
+	switch {
+	case !f.Reachable:
+		fmt.Fprintln(console, "ETA: never / draining (net rate is not positive)")
+	case f.Seconds == 0:
+		fmt.Fprintln(console, "ETA: now (already available)")
+	default:
+		fmt.Fprintf(console, "ETA: %s (in %s)\n",
+			formatTime(f.ETA),
+			time.Duration(f.Seconds*float64(time.Second)).Round(time.Second))
+	}
+}
+
+// PrintComparison prints how the current analysis differs from a previous report
+func PrintComparison(filename string, prev, current tronres.Analysis) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(console, "Comparison with: %s\n", filename)
+	fmt.Fprintln(console, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	fmt.Fprintf(console, "Energy Regen Rate:    %.1f -> %.1f /sec (delta: %+.1f)\n",
+		prev.EnergyRegenRatePerSec,
+		current.EnergyRegenRatePerSec,
+		current.EnergyRegenRatePerSec-prev.EnergyRegenRatePerSec)
+
+	fmt.Fprintf(console, "Energy Consume Rate:  %.1f -> %.1f /sec (delta: %+.1f)\n",
+		prev.EnergyConsumeRatePerSec,
+		current.EnergyConsumeRatePerSec,
+		current.EnergyConsumeRatePerSec-prev.EnergyConsumeRatePerSec)
+
+	fmt.Fprintf(console, "Bandwidth Regen Rate: %.1f -> %.1f /sec (delta: %+.1f)\n",
+		prev.BandwidthRegenRatePerSec,
+		current.BandwidthRegenRatePerSec,
+		current.BandwidthRegenRatePerSec-prev.BandwidthRegenRatePerSec)
+
+	fmt.Fprintf(console, "Tx/day (65k):         %.0f -> %.0f (delta: %+.0f)\n",
+		prev.TxPerDay65k,
+		current.TxPerDay65k,
+		current.TxPerDay65k-prev.TxPerDay65k)
+
+	fmt.Fprintf(console, "Best Fit Model:       %s -> %s (confidence: %s -> %s)\n",
+		formatBestFit(prev.FormulaValidation),
+		formatBestFit(current.FormulaValidation),
+		formatConfidence(prev.FormulaValidation),
+		formatConfidence(current.FormulaValidation))
+
+	fmt.Fprintf(console, "Matches Theory:       energy %s -> %s, bandwidth %s -> %s\n",
+		yesNo(prev.EnergyRateMatchesTheory),
+		yesNo(current.EnergyRateMatchesTheory),
+		yesNo(prev.BandwidthRateMatchesTheory),
+		yesNo(current.BandwidthRateMatchesTheory))
+
+	if bestFitFlipped(prev, current) {
+		fmt.Fprintf(console, "  ! Best fit model flipped from %s to %s, recovery behaviour changed\n",
+			prev.FormulaValidation.BestFit, current.FormulaValidation.BestFit)
+	}
+}
+
+// TrendRun is one column of the table printed by PrintTrend.
+// A zero Start marks the current run.
+type TrendRun struct {
+	Start    time.Time
+	Analysis tronres.Analysis
+}
+
+// PrintTrend prints the key rates of several runs side by side, one column per run
+func PrintTrend(runs []TrendRun) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(console, "Comparison of %d runs\n", len(runs))
+	fmt.Fprintln(console, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	rows := []struct {
+		name  string
+		value func(tronres.Analysis) string
+	}{
+		{"Energy Regen /sec", func(a tronres.Analysis) string { return formatFloat(a.EnergyRegenRatePerSec) }},
+		{"Energy Consume /sec", func(a tronres.Analysis) string { return formatFloat(a.EnergyConsumeRatePerSec) }},
+		{"Bandwidth Regen /sec", func(a tronres.Analysis) string { return formatFloat(a.BandwidthRegenRatePerSec) }},
+		{"Tx/day (65k)", func(a tronres.Analysis) string { return formatNumber(int64(math.Round(a.TxPerDay65k))) }},
+		{"Best fit model", func(a tronres.Analysis) string { return formatBestFit(a.FormulaValidation) }},
+		{"Best fit confidence", func(a tronres.Analysis) string { return formatConfidence(a.FormulaValidation) }},
+		{"Energy matches theory", func(a tronres.Analysis) string { return yesNo(a.EnergyRateMatchesTheory) }},
+		{"BW matches theory", func(a tronres.Analysis) string { return yesNo(a.BandwidthRateMatchesTheory) }},
+	}
+
+	cells := make([][]string, len(rows))
+	widths := make([]int, len(runs))
+	labels := make([]string, len(runs))
+	for i, run := range runs {
+		labels[i] = "current"
+		if !run.Start.IsZero() {
+			labels[i] = run.Start.In(location).Format("2006-01-02 15:04")
+		}
+		widths[i] = len(labels[i])
+	}
+	for r, row := range rows {
+		cells[r] = make([]string, len(runs))
+		for i, run := range runs {
+			cells[r][i] = row.value(run.Analysis)
+			widths[i] = max(widths[i], len(cells[r][i]))
+		}
+	}
+
+	fmt.Fprintf(console, "%-22s", "")
+	for i := range runs {
+		fmt.Fprintf(console, "  %*s", widths[i], labels[i])
+	}
+	fmt.Fprintln(console)
+	for r, row := range rows {
+		fmt.Fprintf(console, "%-22s", row.name)
+		for i := range runs {
+			fmt.Fprintf(console, "  %*s", widths[i], cells[r][i])
+		}
+		fmt.Fprintln(console)
+	}
+
+	// Runs without a fit (no regen measured) don't break the chain
+	prev := -1
+	for i, run := range runs {
+		if run.Analysis.FormulaValidation.BestFit == "" {
+			continue
+		}
+		if prev >= 0 && bestFitFlipped(runs[prev].Analysis, run.Analysis) {
+			fmt.Fprintf(console, "  ! Best fit model flipped from %s to %s between %s and %s\n",
+				runs[prev].Analysis.FormulaValidation.BestFit,
+				run.Analysis.FormulaValidation.BestFit,
+				labels[prev], labels[i])
+		}
+		prev = i
 	}
 }
 
+// bestFitFlipped reports whether the best fit model changed between two runs
+// that both have one
+func bestFitFlipped(prev, current tronres.Analysis) bool {
+	a, b := prev.FormulaValidation.BestFit, current.FormulaValidation.BestFit
+	return a != "" && b != "" && a != b
+}
+
+func formatBestFit(fv tronres.FormulaValidation) string {
+	if fv.BestFit == "" {
+		return "-"
+	}
+	return fv.BestFit
+}
+
+func formatConfidence(fv tronres.FormulaValidation) string {
+	if fv.BestFit == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", fv.Confidence*100)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+// PrintResuming prints which report a resumed session continues
+func PrintResuming(filename string, snapshots int, last time.Time) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(console, "Resuming %s: %d snapshots, last at %s\n",
+		filename, snapshots, formatTime(last))
+}
+
+// PrintReplaying prints which recorded session is analysed again
+func PrintReplaying(filename string, snapshots int, first, last time.Time) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(console, "Replaying %s: %d snapshots from %s to %s\n",
+		filename, snapshots, formatTime(first), formatTime(last))
+}
+
+// PrintSaved prints the name of a file a report was saved to
+func PrintSaved(filename string) {
+	fmt.Fprintf(console, "Log saved to: %s\n", filename)
+}
+
+// formatTime formats a timestamp for the console in the configured time zone
+func formatTime(t time.Time) string {
+	return t.In(location).Format("2006-01-02 15:04:05 MST")
+}
+
 // PrintError prints an error in a formatted way
 func PrintError(err error) {
-	fmt.Printf("\nError: %v\n", err)
+	fmt.Fprintf(console, "\nError: %v\n", err)
+}
+
+// PrintAccountNotFound explains that the node does not know the account
+func PrintAccountNotFound(address string, err error) {
+	fmt.Fprintf(console, "\nError: account %s is not activated or was not found on this node (%v)\n", address, err)
+	fmt.Fprintln(console, "An account becomes active after it receives its first TRX or TRC10 transfer.")
+}
+
+// PrintThresholdFailure prints an alert threshold the account did not meet.
+// address is named for runs with several addresses and may be empty.
+func PrintThresholdFailure(address, failure string) {
+	if address != "" {
+		fmt.Fprintf(console, "\nFAIL: %s: %s\n", address, failure)
+		return
+	}
+	fmt.Fprintf(console, "\nFAIL: %s\n", failure)
 }
 
 // PrintInterrupted prints a message when monitoring is interrupted
 func PrintInterrupted() {
-	fmt.Println("\n\nMonitoring interrupted by user.")
+	fmt.Fprintln(console, "\n\nMonitoring interrupted by user.")
 }
 
 func formatNumber(n int64) string {
@@ -264,7 +851,7 @@ func formatNumber(n int64) string {
 
 	for i, c := range str {
 		if i > 0 && (len(str)-i)%3 == 0 {
-			result += ","
+			result += thousandsSep
 		}
 		result += string(c)
 	}
@@ -272,6 +859,16 @@ func formatNumber(n int64) string {
 	return sign + result
 }
 
+// formatTRX formats a sun amount as TRX with two decimals
+func formatTRX(sun int64) string {
+	trx := sun / 1_000_000
+	cents := (sun % 1_000_000) / 10_000
+	if cents < 0 {
+		cents = -cents
+	}
+	return fmt.Sprintf("%s.%02d", formatNumber(trx), cents)
+}
+
 func formatDelta(n int64) string {
 	if n >= 0 {
 		return "+" + formatNumber(n)
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// SaveCSV saves snapshots to a CSV file (one row per snapshot) and the
+// analysis summary to a sibling "_analysis.csv" file.
+// Returns the paths of both files.
+func SaveCSV(report tronres.MonitorReport, dest Destination) ([]string, error) {
+	filename, err := dest.path(report, ".csv")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeCSV(filename, snapshotRows(report.Snapshots)); err != nil {
+		return nil, err
+	}
+
+	analysisFile := strings.TrimSuffix(filename, ".csv") + "_analysis.csv"
+	if err := writeCSV(analysisFile, analysisRows(report.Analysis)); err != nil {
+		return []string{filename}, err
+	}
+
+	return []string{filename, analysisFile}, nil
+}
+
+func writeCSV(filename string, rows [][]string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return f.Close()
+}
+
+func snapshotRows(snapshots []tronres.Snapshot) [][]string {
+	rows := make([][]string, 0, len(snapshots)+1)
+	rows = append(rows, []string{
+		"timestamp",
+		"elapsed_ms",
+		"energy_available",
+		"energy_limit",
+		"bandwidth_available",
+		"delta_energy",
+		"delta_bandwidth",
+	})
+
+	for _, s := range snapshots {
+		rows = append(rows, []string{
+			s.Timestamp.Format(time.RFC3339Nano),
+			strconv.FormatInt(s.ElapsedMs, 10),
+			strconv.FormatInt(s.EnergyAvailable, 10),
+			strconv.FormatInt(s.EnergyLimit, 10),
+			strconv.FormatInt(s.BandwidthAvailable, 10),
+			strconv.FormatInt(s.DeltaEnergy, 10),
+			strconv.FormatInt(s.DeltaBandwidth, 10),
+		})
+	}
+
+	return rows
+}
+
+func analysisRows(a tronres.Analysis) [][]string {
+	est := a.PracticalEstimates
+
+	return [][]string{
+		{"metric", "value"},
+		{"actual_duration_seconds", formatCSVFloat(a.ActualDurationSec)},
+		{"energy_start", strconv.FormatInt(a.EnergyStart, 10)},
+		{"energy_end", strconv.FormatInt(a.EnergyEnd, 10)},
+		{"energy_regenerated", strconv.FormatInt(a.EnergyRegenerated, 10)},
+		{"energy_consumed", strconv.FormatInt(a.EnergyConsumed, 10)},
+		{"energy_regen_rate_per_second", formatCSVFloat(a.EnergyRegenRatePerSec)},
+		{"energy_consume_rate_per_second", formatCSVFloat(a.EnergyConsumeRatePerSec)},
+		{"energy_net_rate_per_second", formatCSVFloat(a.EnergyNetRatePerSec)},
+		{"bandwidth_start", strconv.FormatInt(a.BandwidthStart, 10)},
+		{"bandwidth_end", strconv.FormatInt(a.BandwidthEnd, 10)},
+		{"bandwidth_regenerated", strconv.FormatInt(a.BandwidthRegenerated, 10)},
+		{"bandwidth_consumed", strconv.FormatInt(a.BandwidthConsumed, 10)},
+		{"bandwidth_regen_rate_per_second", formatCSVFloat(a.BandwidthRegenRatePerSec)},
+		{"bandwidth_consume_rate_per_second", formatCSVFloat(a.BandwidthConsumeRatePerSec)},
+		{"bandwidth_net_rate_per_second", formatCSVFloat(a.BandwidthNetRatePerSec)},
+		{"energy_available_min", strconv.FormatInt(a.EnergyAvailableStats.Min, 10)},
+		{"energy_available_max", strconv.FormatInt(a.EnergyAvailableStats.Max, 10)},
+		{"energy_available_mean", formatCSVFloat(a.EnergyAvailableStats.Mean)},
+		{"energy_available_stddev", formatCSVFloat(a.EnergyAvailableStats.Stddev)},
+		{"bandwidth_available_min", strconv.FormatInt(a.BandwidthAvailableStats.Min, 10)},
+		{"bandwidth_available_max", strconv.FormatInt(a.BandwidthAvailableStats.Max, 10)},
+		{"bandwidth_available_mean", formatCSVFloat(a.BandwidthAvailableStats.Mean)},
+		{"bandwidth_available_stddev", formatCSVFloat(a.BandwidthAvailableStats.Stddev)},
+		{"recovery_ticks", strconv.Itoa(a.TickAnalysis.RecoveryTicks)},
+		{"avg_recovery_interval_sec", formatCSVFloat(a.TickAnalysis.AvgRecoveryInterval)},
+		{"median_recovery_interval_sec", formatCSVFloat(a.TickAnalysis.MedianRecoveryInterval)},
+		{"p95_recovery_interval_sec", formatCSVFloat(a.TickAnalysis.P95RecoveryInterval)},
+		{"recovery_interval_cv", formatCSVFloat(a.TickAnalysis.RecoveryIntervalCV)},
+		{"median_energy_per_tick", formatCSVFloat(a.TickAnalysis.MedianEnergyPerTick)},
+		{"energy_per_tick_stddev", formatCSVFloat(a.TickAnalysis.EnergyPerTickStddev)},
+		{"energy_per_tick_cv", formatCSVFloat(a.TickAnalysis.EnergyPerTickCV)},
+		{"consumption_events", strconv.Itoa(a.TickAnalysis.ConsumptionEvents)},
+		{"tx_per_day_65k_sustained", formatCSVFloat(est.TxPerDay65kSustained)},
+		{"tx_per_day_131k_sustained", formatCSVFloat(est.TxPerDay131kSustained)},
+		{"tx_per_day_65k_with_buffer", formatCSVFloat(est.TxPerDay65kWithBuffer)},
+		{"tx_per_day_131k_with_buffer", formatCSVFloat(est.TxPerDay131kWithBuffer)},
+		{"immediate_capacity_65k", strconv.FormatInt(est.ImmediateCapacity65k, 10)},
+		{"immediate_capacity_131k", strconv.FormatInt(est.ImmediateCapacity131k, 10)},
+	}
+}
+
+func formatCSVFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
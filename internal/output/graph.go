@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+const (
+	defaultGraphWidth = 80
+	graphIndent       = "    "
+)
+
+var (
+	// sparkBlocks are the levels of a sparkline on a terminal
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+	// sparkASCII are the levels used when output is redirected, where the
+	// block characters may end up in a log that doesn't render them
+	sparkASCII = []rune("_.-:=+*#")
+)
+
+// PrintGraph prints sparklines of the energy and bandwidth available across
+// the snapshots, fitted to the terminal width (or $COLUMNS, default 80).
+// Longer runs are averaged into one column per bucket; shorter ones get one
+// column per snapshot. When the console is not a terminal, ASCII is used.
+func PrintGraph(snapshots []tronres.Snapshot) {
+	if len(snapshots) < 2 {
+		return
+	}
+
+	width := graphWidth() - len(graphIndent)
+	levels := sparkASCII
+	if isTerminal(console) {
+		levels = sparkBlocks
+	}
+
+	energy := make([]int64, len(snapshots))
+	bandwidth := make([]int64, len(snapshots))
+	for i, s := range snapshots {
+		energy[i] = s.EnergyAvailable
+		bandwidth[i] = s.BandwidthAvailable
+	}
+
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "  Availability Graph:")
+	printSparkline("Energy", energy, width, levels)
+	printSparkline("Bandwidth", bandwidth, width, levels)
+}
+
+func printSparkline(label string, values []int64, width int, levels []rune) {
+	points := downsample(values, width)
+
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo = min(lo, p)
+		hi = max(hi, p)
+	}
+
+	line := make([]rune, len(points))
+	for i, p := range points {
+		level := len(levels) / 2 // a flat line sits in the middle
+		if hi > lo {
+			level = int((p - lo) / (hi - lo) * float64(len(levels)-1))
+		}
+		line[i] = levels[level]
+	}
+
+	fmt.Fprintf(console, "    %s (%s .. %s):\n", label, formatNumber(int64(lo)), formatNumber(int64(hi)))
+	fmt.Fprintf(console, "%s%s\n", graphIndent, string(line))
+}
+
+// downsample averages values into at most width buckets of near-equal size
+func downsample(values []int64, width int) []float64 {
+	width = max(width, 1)
+	n := min(len(values), width)
+
+	points := make([]float64, n)
+	for i := range points {
+		from := i * len(values) / n
+		to := (i + 1) * len(values) / n
+		var sum int64
+		for _, v := range values[from:to] {
+			sum += v
+		}
+		points[i] = float64(sum) / float64(to-from)
+	}
+	return points
+}
+
+// graphWidth is the width of the terminal the console writes to, else
+// $COLUMNS, else defaultGraphWidth
+func graphWidth() int {
+	if f, ok := console.(*os.File); ok && isTerminal(f) {
+		if n := terminalWidth(f); n > len(graphIndent) {
+			return n
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && n > len(graphIndent) {
+		return n
+	}
+	return defaultGraphWidth
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
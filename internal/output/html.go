@@ -0,0 +1,232 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+const (
+	chartWidth     = 800
+	chartHeight    = 220
+	chartPadLeft   = 80
+	chartPadRight  = 10
+	chartPadTop    = 10
+	chartPadBottom = 25
+
+	// maxChartBuckets caps the points per line, so long runs stay a small file
+	maxChartBuckets = 500
+)
+
+const htmlStyle = `body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:2em auto;max-width:860px;color:#222}
+h1{font-size:1.6em}h2{font-size:1.2em;margin-top:1.6em}
+table{border-collapse:collapse;margin:.5em 0}th,td{border:1px solid #ccc;padding:4px 8px}
+th{background:#f3f3f3}td.num{text-align:right;font-variant-numeric:tabular-nums}
+svg{display:block;margin:.5em 0}svg text{font-size:11px;fill:#555}
+.grid{stroke:#e5e5e5}.avail{fill:none;stroke:#1f77b4;stroke-width:1.5}.limit{fill:none;stroke:#d62728;stroke-dasharray:4 3}
+.legend span{display:inline-block;margin-right:1.5em}.swatch{display:inline-block;width:14px;height:3px;vertical-align:middle;margin-right:4px}`
+
+// SaveHTML saves a report as a self-contained HTML page with line charts of
+// the available energy and bandwidth (inline SVG) and the summary tables.
+// It loads nothing from the network, so it opens fine offline.
+// Returns the path of the written file.
+func SaveHTML(report tronres.MonitorReport, dest Destination) (string, error) {
+	filename, err := dest.path(report, ".html")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filename, []byte(renderHTML(report)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return filename, nil
+}
+
+func renderHTML(report tronres.MonitorReport) string {
+	var b strings.Builder
+	est := report.Analysis.PracticalEstimates
+	title := "TRON Resource Report " + report.Metadata.Address
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyle)
+	b.WriteString("<h1>TRON Resource Report</h1>\n")
+
+	for i, t := range reportTables(report) {
+		if t.title != "" {
+			fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(t.title))
+		}
+		writeHTMLTable(&b, t.header, t.rows)
+
+		// The charts go right below the metadata
+		if i == 0 {
+			writeCharts(&b, report.Snapshots)
+		}
+	}
+
+	fmt.Fprintf(&b, "<p>TRX transfers (%d bandwidth each): %s tx/day (staked %s, free %s).</p>\n",
+		tronres.TransferBandwidthCost,
+		formatRounded(est.TxPerDayTransfer),
+		formatRounded(est.TxPerDayTransferStaked),
+		formatRounded(est.TxPerDayTransferFree),
+	)
+	if est.TrxBurnedEstimate > 0 {
+		fmt.Fprintf(&b, "<p>TRX burn equivalent of the observed consumption: %.2f TRX (%.2f TRX/day).</p>\n",
+			est.TrxBurnedEstimate, est.TrxBurnedPerDayEstimate)
+	}
+
+	if warnings := warningLines(report.Analysis); len(warnings) > 0 {
+		b.WriteString("<h2>Warnings</h2>\n<ul>\n")
+		for _, w := range warnings {
+			fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(w))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// writeHTMLTable writes a table, numbers right-aligned after the first column
+// like the Markdown report
+func writeHTMLTable(b *strings.Builder, header []string, rows [][]string) {
+	freeForm := len(header) == 2 && header[1] == "Value"
+
+	b.WriteString("<table>\n<tr>")
+	for _, h := range header {
+		fmt.Fprintf(b, "<th>%s</th>", html.EscapeString(h))
+	}
+	b.WriteString("</tr>\n")
+
+	for _, row := range rows {
+		b.WriteString("<tr>")
+		for i, cell := range row {
+			if i > 0 && !freeForm {
+				fmt.Fprintf(b, "<td class=\"num\">%s</td>", html.EscapeString(cell))
+			} else {
+				fmt.Fprintf(b, "<td>%s</td>", html.EscapeString(cell))
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// chartPoint is one point of a chart line: elapsed ms and value
+type chartPoint struct {
+	x int64
+	y int64
+}
+
+func writeCharts(b *strings.Builder, snapshots []tronres.Snapshot) {
+	if len(snapshots) < 2 {
+		b.WriteString("<p>Not enough samples for a chart.</p>\n")
+		return
+	}
+
+	energy := make([]chartPoint, len(snapshots))
+	energyLimit := make([]chartPoint, len(snapshots))
+	bandwidth := make([]chartPoint, len(snapshots))
+	bandwidthLimit := make([]chartPoint, len(snapshots))
+	for i, s := range snapshots {
+		energy[i] = chartPoint{s.ElapsedMs, s.EnergyAvailable}
+		energyLimit[i] = chartPoint{s.ElapsedMs, s.EnergyLimit}
+		bandwidth[i] = chartPoint{s.ElapsedMs, s.BandwidthAvailable}
+		bandwidthLimit[i] = chartPoint{s.ElapsedMs, s.TotalBandwidthLimit()}
+	}
+
+	b.WriteString("<h2>Energy Available</h2>\n")
+	writeChart(b, energy, energyLimit)
+	b.WriteString("<h2>Bandwidth Available</h2>\n")
+	writeChart(b, bandwidth, bandwidthLimit)
+}
+
+// writeChart writes an SVG line chart of available against the limit, the
+// y axis running from 0 to the highest value
+func writeChart(b *strings.Builder, available, limit []chartPoint) {
+	available = reducePoints(available, maxChartBuckets)
+	limit = reducePoints(limit, maxChartBuckets)
+
+	x0, x1 := available[0].x, available[len(available)-1].x
+	var top int64
+	for _, p := range append(available, limit...) {
+		top = max(top, p.y)
+	}
+	top = max(top, 1)
+
+	plotW := float64(chartWidth - chartPadLeft - chartPadRight)
+	plotH := float64(chartHeight - chartPadTop - chartPadBottom)
+	scaleX := func(x int64) float64 {
+		if x1 == x0 {
+			return chartPadLeft
+		}
+		return chartPadLeft + float64(x-x0)/float64(x1-x0)*plotW
+	}
+	scaleY := func(y int64) float64 {
+		return chartPadTop + plotH - float64(y)/float64(top)*plotH
+	}
+
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\" width=\"100%%\" role=\"img\">\n", chartWidth, chartHeight)
+
+	// Grid lines at 0, 50% and 100% of the y range
+	for _, frac := range []float64{0, 0.5, 1} {
+		v := int64(float64(top) * frac)
+		y := scaleY(v)
+		fmt.Fprintf(b, "<line class=\"grid\" x1=\"%d\" y1=\"%.1f\" x2=\"%d\" y2=\"%.1f\"/>\n", chartPadLeft, y, chartWidth-chartPadRight, y)
+		fmt.Fprintf(b, "<text x=\"%d\" y=\"%.1f\" text-anchor=\"end\" dominant-baseline=\"middle\">%s</text>\n", chartPadLeft-6, y, formatNumber(v))
+	}
+	fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">T+%.0fs</text>\n", chartPadLeft, chartHeight-6, float64(x0)/1000)
+	fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"end\">T+%.0fs</text>\n", chartWidth-chartPadRight, chartHeight-6, float64(x1)/1000)
+
+	for _, line := range []struct {
+		class  string
+		points []chartPoint
+	}{{"limit", limit}, {"avail", available}} {
+		fmt.Fprintf(b, "<polyline class=\"%s\" points=\"", line.class)
+		for i, p := range line.points {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(b, "%.1f,%.1f", scaleX(p.x), scaleY(p.y))
+		}
+		b.WriteString("\"/>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	b.WriteString("<p class=\"legend\"><span><i class=\"swatch\" style=\"background:#1f77b4\"></i>available</span>" +
+		"<span><i class=\"swatch\" style=\"background:#d62728\"></i>limit</span></p>\n")
+}
+
+// reducePoints keeps the lowest and highest point of each of at most
+// buckets groups, in time order, so dips and peaks survive on long runs
+func reducePoints(points []chartPoint, buckets int) []chartPoint {
+	if len(points) <= 2*buckets {
+		return points
+	}
+
+	reduced := make([]chartPoint, 0, 2*buckets)
+	for i := range buckets {
+		group := points[i*len(points)/buckets : (i+1)*len(points)/buckets]
+		lo, hi := 0, 0
+		for j, p := range group {
+			if p.y < group[lo].y {
+				lo = j
+			}
+			if p.y > group[hi].y {
+				hi = j
+			}
+		}
+		switch {
+		case lo == hi:
+			reduced = append(reduced, group[lo])
+		case lo < hi:
+			reduced = append(reduced, group[lo], group[hi])
+		default:
+			reduced = append(reduced, group[hi], group[lo])
+		}
+	}
+	return reduced
+}
@@ -0,0 +1,184 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+const (
+	// DefaultInfluxMeasurement is the measurement of the line protocol output
+	DefaultInfluxMeasurement = "tron_resources"
+
+	influxTimeout = 10 * time.Second
+)
+
+// InfluxOptions names the measurement and tags of the line protocol output.
+// Empty names fall back to tron_resources, address and node.
+type InfluxOptions struct {
+	Measurement string
+	AddressTag  string
+	NodeTag     string
+}
+
+func (o InfluxOptions) withDefaults() InfluxOptions {
+	if o.Measurement == "" {
+		o.Measurement = DefaultInfluxMeasurement
+	}
+	if o.AddressTag == "" {
+		o.AddressTag = "address"
+	}
+	if o.NodeTag == "" {
+		o.NodeTag = "node"
+	}
+	return o
+}
+
+// WriteInflux writes one InfluxDB line protocol point per snapshot of the
+// report, timestamped with the snapshot time in nanoseconds
+func WriteInflux(w io.Writer, report tronres.MonitorReport, opts InfluxOptions) error {
+	opts = opts.withDefaults()
+
+	// The series key is the same for every line. InfluxDB rejects a tag
+	// without a value, e.g. the node of an analyzed file without metadata.
+	series := escapeInfluxMeasurement(opts.Measurement)
+	for _, tag := range []struct{ key, value string }{
+		{opts.AddressTag, report.Metadata.Address},
+		{opts.NodeTag, report.Metadata.Node},
+	} {
+		if tag.value != "" {
+			series += "," + escapeInfluxTag(tag.key) + "=" + escapeInfluxTag(tag.value)
+		}
+	}
+
+	var b strings.Builder
+	for _, s := range report.Snapshots {
+		b.Reset()
+		b.WriteString(series)
+		b.WriteByte(' ')
+		for i, f := range influxFields(s) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(f.key)
+			b.WriteByte('=')
+			b.WriteString(strconv.FormatInt(f.value, 10))
+			b.WriteByte('i')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(s.Timestamp.UnixNano(), 10))
+		b.WriteByte('\n')
+
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveInflux saves the snapshots of the report as InfluxDB line protocol
+func SaveInflux(report tronres.MonitorReport, dest Destination, opts InfluxOptions) (string, error) {
+	filename, err := dest.path(report, ".lp")
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteInflux(file, report, opts); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return filename, file.Close()
+}
+
+// PostInflux sends the snapshots of the report to an InfluxDB write endpoint,
+// e.g. http://localhost:8086/api/v2/write?org=o&bucket=b&precision=ns.
+// token, if not empty, is sent as "Authorization: Token <token>". proxy is
+// the proxy to send it through, when nil HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY from the environment apply.
+func PostInflux(ctx context.Context, endpoint, token string, proxy *url.URL, report tronres.MonitorReport, opts InfluxOptions) error {
+	var body bytes.Buffer
+	if err := WriteInflux(&body, report, opts); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	client := &http.Client{Timeout: influxTimeout, Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// InfluxDB answers a successful write with 204 No Content
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+type influxField struct {
+	key   string
+	value int64
+}
+
+func influxFields(s tronres.Snapshot) []influxField {
+	return []influxField{
+		{"energy_available", s.EnergyAvailable},
+		{"energy_limit", s.EnergyLimit},
+		{"energy_used", s.EnergyUsed},
+		{"bandwidth_available", s.BandwidthAvailable},
+		{"bandwidth_limit", s.TotalBandwidthLimit()},
+		{"staked_bandwidth_available", s.StakedBandwidthAvailable},
+		{"free_bandwidth_available", s.FreeBandwidthAvailable},
+		{"net_limit", s.NetLimit},
+		{"net_used", s.NetUsed},
+		{"free_net_limit", s.FreeNetLimit},
+		{"free_net_used", s.FreeNetUsed},
+		{"delta_energy", s.DeltaEnergy},
+		{"delta_bandwidth", s.DeltaBandwidth},
+	}
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+func escapeInfluxMeasurement(s string) string {
+	return influxMeasurementEscaper.Replace(s)
+}
+
+func escapeInfluxTag(s string) string {
+	return influxTagEscaper.Replace(s)
+}
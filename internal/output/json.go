@@ -3,15 +3,78 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"time"
 
-	"github.com/sxwebdev/tron-resource-calculator/internal/models"
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
 )
 
+// Destination controls where report files are written
+type Destination struct {
+	// Dir is the output directory; empty means the current directory
+	Dir string
+	// File is an explicit file name. Relative names are placed inside Dir.
+	// Empty means a generated timestamped name.
+	File string
+	// Primary is the extension of the main output. An absolute File is used
+	// as-is for it; the other outputs, e.g. the CSV of --format both, get
+	// their extension swapped in or appended.
+	Primary string
+	// Location is the time zone of the timestamp in generated names.
+	// Nil keeps the zone of the start time.
+	Location *time.Location
+}
+
+// path resolves the file path for the given extension and makes sure
+// the parent directory exists
+func (d Destination) path(report tronres.MonitorReport, ext string) (string, error) {
+	var filename string
+	if d.File == "" {
+		startTime := report.Metadata.StartTime
+		if d.Location != nil {
+			startTime = startTime.In(d.Location)
+		}
+		filename = filepath.Join(d.Dir, generateFilename(report.Metadata.Address, startTime, ext))
+	} else {
+		filename = d.File
+		if !filepath.IsAbs(filename) || ext != d.Primary {
+			filename = withExt(filename, ext)
+		}
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(d.Dir, filename)
+		}
+	}
+
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	return filename, nil
+}
+
+// withExt makes sure name carries ext. A known report extension is swapped
+// (so --out-file run.json also yields run.csv), anything else gets ext appended.
+func withExt(name, ext string) string {
+	switch current := filepath.Ext(name); current {
+	case ext:
+		return name
+	case ".json", ".csv", ".ndjson", ".md", ".html", ".lp":
+		return name[:len(name)-len(current)] + ext
+	default:
+		return name + ext
+	}
+}
+
 // SaveJSON saves the monitoring report to a JSON file
-func SaveJSON(report models.MonitorReport) (string, error) {
-	filename := generateFilename(report.Metadata.Address, report.Metadata.StartTime)
+func SaveJSON(report tronres.MonitorReport, dest Destination) (string, error) {
+	filename, err := dest.path(report, ".json")
+	if err != nil {
+		return "", err
+	}
 
 	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
@@ -25,15 +88,23 @@ func SaveJSON(report models.MonitorReport) (string, error) {
 	return filename, nil
 }
 
-func generateFilename(address string, startTime time.Time) string {
-	// Use first 4 and last 4 characters of address for short version
-	shortAddr := address
-	if len(address) > 8 {
-		shortAddr = address[:4] + "..." + address[len(address)-4:]
+// WriteJSON writes the report to w in the same format as SaveJSON
+func WriteJSON(w io.Writer, report tronres.MonitorReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
 	}
 
+	return nil
+}
+
+func generateFilename(address string, startTime time.Time, ext string) string {
 	timestamp := startTime.Format("20060102_150405")
-	return fmt.Sprintf("tron_monitor_%s_%s.json", shortAddr, timestamp)
+	return fmt.Sprintf("tron_monitor_%s_%s%s", ShortAddress(address), timestamp, ext)
 }
 
 // BuildReport creates a MonitorReport from collected data
@@ -41,11 +112,11 @@ func BuildReport(
 	address, node string,
 	startTime, endTime time.Time,
 	duration int,
-	snapshots []models.ResourceSnapshot,
-	analysis models.Analysis,
-) models.MonitorReport {
-	return models.MonitorReport{
-		Metadata: models.Metadata{
+	snapshots []tronres.Snapshot,
+	analysis tronres.Analysis,
+) tronres.MonitorReport {
+	return tronres.MonitorReport{
+		Metadata: tronres.Metadata{
 			Address:         address,
 			Node:            node,
 			StartTime:       startTime,
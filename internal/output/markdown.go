@@ -0,0 +1,197 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// SaveMarkdown saves the metadata and analysis of a report as Markdown
+// tables, meant for pasting into issues and pull requests. The output only
+// depends on the report, so reports of the same data diff cleanly.
+// Returns the path of the written file.
+func SaveMarkdown(report tronres.MonitorReport, dest Destination) (string, error) {
+	filename, err := dest.path(report, ".md")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filename, []byte(renderMarkdown(report)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return filename, nil
+}
+
+func renderMarkdown(report tronres.MonitorReport) string {
+	var b strings.Builder
+	a := report.Analysis
+	est := a.PracticalEstimates
+
+	tables := reportTables(report)
+	tables[0].rows[0][1] = "`" + report.Metadata.Address + "`"
+
+	b.WriteString("# TRON Resource Report\n")
+	for _, t := range tables {
+		if t.title != "" {
+			fmt.Fprintf(&b, "\n## %s\n", t.title)
+		}
+		b.WriteString("\n")
+		writeTable(&b, t.header, t.rows)
+	}
+	fmt.Fprintf(&b, "\nTRX transfers (%d bandwidth each): %s tx/day (staked %s, free %s).\n",
+		tronres.TransferBandwidthCost,
+		formatRounded(est.TxPerDayTransfer),
+		formatRounded(est.TxPerDayTransferStaked),
+		formatRounded(est.TxPerDayTransferFree),
+	)
+	if est.TrxBurnedEstimate > 0 {
+		fmt.Fprintf(&b, "\nTRX burn equivalent of the observed consumption: %.2f TRX (%.2f TRX/day).\n",
+			est.TrxBurnedEstimate, est.TrxBurnedPerDayEstimate)
+	}
+
+	if warnings := warningLines(a); len(warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, w := range warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+	}
+
+	return b.String()
+}
+
+// reportTable is one table of the Markdown and HTML reports
+type reportTable struct {
+	title  string // empty for the metadata table under the report heading
+	header []string
+	rows   [][]string
+}
+
+// reportTables returns the metadata and analysis tables of a report
+func reportTables(report tronres.MonitorReport) []reportTable {
+	m := report.Metadata
+	a := report.Analysis
+	tick := a.TickAnalysis
+	est := a.PracticalEstimates
+
+	return []reportTable{
+		{
+			header: []string{"Field", "Value"},
+			rows: [][]string{
+				{"Address", m.Address},
+				{"Node", m.Node},
+				{"Started", m.StartTime.UTC().Format("2006-01-02 15:04:05 UTC")},
+				{"Ended", m.EndTime.UTC().Format("2006-01-02 15:04:05 UTC")},
+				{"Duration", fmt.Sprintf("%.1f sec", a.ActualDurationSec)},
+				{"Samples", formatNumber(int64(m.SamplesCount))},
+				{"Interval", fmt.Sprintf("%d ms (actual %.0f ± %.0f ms)", m.IntervalMs, m.ActualIntervalMeanMs, m.ActualIntervalStddevMs)},
+			},
+		},
+		{
+			title:  "Rates",
+			header: []string{"Resource", "Regen /sec", "Regen /day", "Consume /sec", "Consume /day", "Net /sec", "Net /day"},
+			rows: [][]string{
+				{
+					"Energy",
+					formatFloat(a.EnergyRegenRatePerSec), formatRounded(a.EnergyRegenRatePerDay),
+					formatFloat(a.EnergyConsumeRatePerSec), formatRounded(a.EnergyConsumeRatePerDay),
+					formatFloat(a.EnergyNetRatePerSec), formatDelta(int64(math.Round(a.EnergyNetRatePerDay))),
+				},
+				{
+					"Bandwidth",
+					formatFloat(a.BandwidthRegenRatePerSec), formatRounded(a.BandwidthRegenRatePerDay),
+					formatFloat(a.BandwidthConsumeRatePerSec), formatRounded(a.BandwidthConsumeRatePerDay),
+					formatFloat(a.BandwidthNetRatePerSec), formatDelta(int64(math.Round(a.BandwidthNetRatePerDay))),
+				},
+			},
+		},
+		{
+			title:  "Totals",
+			header: []string{"Resource", "Start", "End", "Min", "Max", "Regenerated", "Consumed", "Net"},
+			rows: [][]string{
+				{
+					"Energy",
+					formatNumber(a.EnergyStart), formatNumber(a.EnergyEnd),
+					formatNumber(a.EnergyAvailableStats.Min), formatNumber(a.EnergyAvailableStats.Max),
+					formatNumber(a.EnergyRegenerated), formatNumber(a.EnergyConsumed), formatDelta(a.EnergyTotalDelta),
+				},
+				{
+					"Bandwidth",
+					formatNumber(a.BandwidthStart), formatNumber(a.BandwidthEnd),
+					formatNumber(a.BandwidthAvailableStats.Min), formatNumber(a.BandwidthAvailableStats.Max),
+					formatNumber(a.BandwidthRegenerated), formatNumber(a.BandwidthConsumed), formatDelta(a.BandwidthTotalDelta),
+				},
+			},
+		},
+		{
+			title:  "Tick Analysis",
+			header: []string{"Metric", "Value"},
+			rows: [][]string{
+				{"Recovery ticks", formatNumber(int64(tick.RecoveryTicks))},
+				{"Avg / median interval", fmt.Sprintf("%.1f / %.1f sec", tick.AvgRecoveryInterval, tick.MedianRecoveryInterval)},
+				{"Interval p90 / p95", fmt.Sprintf("%.1f / %.1f sec", tick.P90RecoveryInterval, tick.P95RecoveryInterval)},
+				{"Ticks per day", formatRounded(tick.RecoveryTicksPerDay)},
+				{"Interval stddev (CV)", fmt.Sprintf("%.1f sec (%.0f%%)", tick.RecoveryIntervalStddev, tick.RecoveryIntervalCV*100)},
+				{"Avg / median energy per tick", formatRounded(tick.EnergyPerTick) + " / " + formatRounded(tick.MedianEnergyPerTick)},
+				{"Energy per tick stddev (CV)", fmt.Sprintf("%s (%.0f%%)", formatRounded(tick.EnergyPerTickStddev), tick.EnergyPerTickCV*100)},
+				{"Bandwidth per tick", fmt.Sprintf("%.1f", tick.BandwidthPerTick)},
+				{"Consumption events", formatNumber(int64(tick.ConsumptionEvents))},
+				{"Energy consumed", formatNumber(tick.TotalEnergyConsumed)},
+				{"Bandwidth consumed", formatNumber(tick.TotalBandwidthConsumed)},
+			},
+		},
+		{
+			title:  "Practical Estimates",
+			header: []string{"Estimate", "65k energy/tx", "131k energy/tx"},
+			rows: [][]string{
+				{"Immediate (from buffer)", formatNumber(est.ImmediateCapacity65k) + " tx", formatNumber(est.ImmediateCapacity131k) + " tx"},
+				{"Sustained (regen only)", formatRounded(est.TxPerDay65kSustained) + " tx/day", formatRounded(est.TxPerDay131kSustained) + " tx/day"},
+				{"With buffer", formatRounded(est.TxPerDay65kWithBuffer) + " tx/day", formatRounded(est.TxPerDay131kWithBuffer) + " tx/day"},
+				{"Energy for 800 tx", formatNumber(est.EnergyNeeded800Tx65k), formatNumber(est.EnergyNeeded800Tx131k)},
+			},
+		},
+	}
+}
+
+// warningLines returns the analysis warnings and limit changes of a report
+func warningLines(a tronres.Analysis) []string {
+	lines := append([]string(nil), a.Warnings...)
+	for _, e := range a.LimitChangeEvents {
+		lines = append(lines, fmt.Sprintf("%s limit changed at T+%.1fs: %s -> %s",
+			e.Resource, float64(e.ElapsedMs)/1000.0, formatNumber(e.OldLimit), formatNumber(e.NewLimit)))
+	}
+	return lines
+}
+
+// writeTable writes a Markdown table, numbers right-aligned after the first column
+func writeTable(b *strings.Builder, header []string, rows [][]string) {
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+
+	align := make([]string, len(header))
+	for i := range align {
+		align[i] = "---:"
+		if i == 0 {
+			align[i] = "---"
+		}
+	}
+	if len(header) == 2 && header[1] == "Value" {
+		align[1] = "---" // free-form values read better left-aligned
+	}
+	b.WriteString("| " + strings.Join(align, " | ") + " |\n")
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "|", "\\|")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// formatRounded formats a float like an integer count, rounding like the console's %.0f
+func formatRounded(f float64) string {
+	return formatNumber(int64(math.Round(f)))
+}
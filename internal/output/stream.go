@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// StreamWriter appends snapshots to an NDJSON file (one JSON object per line)
+// as they are taken, so a killed process loses at most the current sample
+type StreamWriter struct {
+	filename string
+	file     *os.File
+	buf      *bufio.Writer
+	enc      *json.Encoder
+}
+
+// OpenStream creates the NDJSON stream file for a monitoring session. An
+// existing file of that name is truncated, a stream holds one session only.
+func OpenStream(dest Destination, address string, startTime time.Time) (*StreamWriter, error) {
+	stub := tronres.MonitorReport{Metadata: tronres.Metadata{Address: address, StartTime: startTime}}
+	filename, err := dest.path(stub, ".ndjson")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream file: %w", err)
+	}
+
+	buf := bufio.NewWriter(f)
+	return &StreamWriter{
+		filename: filename,
+		file:     f,
+		buf:      buf,
+		enc:      json.NewEncoder(buf),
+	}, nil
+}
+
+// Filename returns the path of the stream file
+func (w *StreamWriter) Filename() string {
+	return w.filename
+}
+
+// Write appends one snapshot and flushes it to the file
+func (w *StreamWriter) Write(snapshot tronres.Snapshot) error {
+	if err := w.enc.Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush stream: %w", err)
+	}
+	return nil
+}
+
+// ReadStream reads back the snapshots of an NDJSON stream file. On a
+// malformed line it returns the snapshots before it along with the error.
+func ReadStream(filename string) ([]tronres.Snapshot, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream file: %w", err)
+	}
+	defer f.Close()
+
+	var snapshots []tronres.Snapshot
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var snapshot tronres.Snapshot
+		if err := dec.Decode(&snapshot); err == io.EOF {
+			return snapshots, nil
+		} else if err != nil {
+			return snapshots, fmt.Errorf("failed to read %s after %d snapshots: %w", filename, len(snapshots), err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+}
+
+// Close flushes and closes the stream file
+func (w *StreamWriter) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to flush stream: %w", err)
+	}
+	return w.file.Close()
+}
+
+// SaveAnalysisJSON saves the report without snapshots to a ".analysis.json" file.
+// Used in stream mode where the snapshots are already on disk as NDJSON.
+func SaveAnalysisJSON(report tronres.MonitorReport, dest Destination) (string, error) {
+	report.Snapshots = nil
+
+	filename, err := dest.path(report, ".analysis.json")
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package output
+
+import "os"
+
+// terminalWidth is not detected on this platform, $COLUMNS or the default applies
+func terminalWidth(f *os.File) int {
+	return 0
+}
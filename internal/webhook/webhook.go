@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+const timeout = 10 * time.Second
+
+// Event types sent in Payload.Event
+const (
+	EventFullRecovery       = "full_recovery"
+	EventEnergyThreshold    = "energy_threshold"
+	EventBandwidthThreshold = "bandwidth_threshold"
+)
+
+// Payload is the JSON body POSTed for every event
+type Payload struct {
+	Event              string    `json:"event"`
+	Address            string    `json:"address"`
+	Timestamp          time.Time `json:"timestamp"`
+	ElapsedMs          int64     `json:"elapsed_ms"`
+	Threshold          int64     `json:"threshold,omitempty"`
+	EnergyAvailable    int64     `json:"energy_available"`
+	EnergyLimit        int64     `json:"energy_limit"`
+	BandwidthAvailable int64     `json:"bandwidth_available"`
+	BandwidthLimit     int64     `json:"bandwidth_limit"`
+}
+
+// Options configures a Notifier. Zero thresholds are off.
+type Options struct {
+	// EnergyThreshold fires an event when the available energy rises to it
+	EnergyThreshold int64
+	// BandwidthThreshold fires an event when the available bandwidth rises to it
+	BandwidthThreshold int64
+	// RecoveryTarget decides when the full recovery event fires, the zero
+	// value waits until no energy and no bandwidth is used
+	RecoveryTarget tronres.RecoveryTarget
+}
+
+// addressState remembers what the previous snapshot of an address looked
+// like, so every event fires once per crossing
+type addressState struct {
+	full           bool
+	energyAbove    bool
+	bandwidthAbove bool
+}
+
+// Notifier POSTs a JSON payload to a webhook when an account reaches its
+// recovery target or a threshold is crossed. Deliveries run in the background, a failure is
+// printed as a warning and never stops monitoring.
+type Notifier struct {
+	url    string
+	opts   Options
+	client *http.Client
+
+	mu        sync.Mutex
+	addresses map[string]*addressState
+	pending   sync.WaitGroup
+}
+
+// NewNotifier creates a Notifier for the webhook at url
+func NewNotifier(url string, opts Options) *Notifier {
+	return &Notifier{
+		url:       url,
+		opts:      opts,
+		client:    &http.Client{Timeout: timeout},
+		addresses: make(map[string]*addressState),
+	}
+}
+
+// Observe checks a snapshot of address for events and sends them.
+// The first snapshot only sets the starting state for thresholds, so an
+// account that is already above a threshold doesn't fire; full recovery
+// fires on the first snapshot that reaches the recovery target as well.
+func (n *Notifier) Observe(address string, snapshot tronres.Snapshot) {
+	n.mu.Lock()
+	state, seen := n.addresses[address]
+	if !seen {
+		state = &addressState{}
+		n.addresses[address] = state
+	}
+
+	var events []Payload
+	full := n.opts.RecoveryTarget.Reached(snapshot)
+	if full && !state.full {
+		events = append(events, payload(EventFullRecovery, address, 0, snapshot))
+	}
+	state.full = full
+
+	if t := n.opts.EnergyThreshold; t > 0 {
+		above := snapshot.EnergyAvailable >= t
+		if above && seen && !state.energyAbove {
+			events = append(events, payload(EventEnergyThreshold, address, t, snapshot))
+		}
+		state.energyAbove = above
+	}
+	if t := n.opts.BandwidthThreshold; t > 0 {
+		above := snapshot.BandwidthAvailable >= t
+		if above && seen && !state.bandwidthAbove {
+			events = append(events, payload(EventBandwidthThreshold, address, t, snapshot))
+		}
+		state.bandwidthAbove = above
+	}
+	n.mu.Unlock()
+
+	for _, event := range events {
+		n.pending.Add(1)
+		go func() {
+			defer n.pending.Done()
+			if err := n.send(event); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to deliver %s webhook for %s: %v\n", event.Event, event.Address, err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until the events sent so far are delivered or have failed
+func (n *Notifier) Wait() {
+	n.pending.Wait()
+}
+
+func payload(event, address string, threshold int64, s tronres.Snapshot) Payload {
+	return Payload{
+		Event:              event,
+		Address:            address,
+		Timestamp:          s.Timestamp,
+		ElapsedMs:          s.ElapsedMs,
+		Threshold:          threshold,
+		EnergyAvailable:    s.EnergyAvailable,
+		EnergyLimit:        s.EnergyLimit,
+		BandwidthAvailable: s.BandwidthAvailable,
+		BandwidthLimit:     s.TotalBandwidthLimit(),
+	}
+}
+
+func (n *Notifier) send(event Payload) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sxwebdev/tron-resource-calculator/tronres"
+)
+
+// stubClient answers the polls with responses in order, repeating the last one
+type stubClient struct {
+	mu        sync.Mutex
+	calls     int
+	responses []tronres.APIResponse
+}
+
+func (c *stubClient) GetAccountResource(ctx context.Context, address string) (*tronres.APIResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp := c.responses[min(c.calls, len(c.responses)-1)]
+	c.calls++
+	return &resp, nil
+}
+
+func TestFullRecoveryAtTarget(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Payload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		events = append(events, p)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	// Bandwidth stays in use, energy drops to 1% of its limit on the third poll
+	var responses []tronres.APIResponse
+	for _, used := range []int64{3000, 2000, 1000, 0} {
+		responses = append(responses, tronres.APIResponse{EnergyLimit: 100000, EnergyUsed: used, FreeNetLimit: 600, FreeNetUsed: 300})
+	}
+	target := tronres.RecoveryTarget{Resource: tronres.RecoverEnergy, ThresholdPercent: 1}
+
+	notifier := NewNotifier(server.URL, Options{RecoveryTarget: target})
+	m := tronres.NewMonitorWithInterval(&stubClient{responses: responses}, "TTest", 0, 100)
+	m.SetRecoveryTarget(target)
+
+	snapshots, err := m.RunUntilFull(context.Background(), 10, func(s tronres.Snapshot, index int) {
+		notifier.Observe("TTest", s)
+	})
+	if err != nil {
+		t.Fatalf("RunUntilFull: %v", err)
+	}
+	notifier.Wait()
+
+	if len(snapshots) != 3 {
+		t.Fatalf("got %d snapshots, want 3 (stop at the threshold)", len(snapshots))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 || events[0].Event != EventFullRecovery {
+		t.Fatalf("got events %+v, want one %s", events, EventFullRecovery)
+	}
+	if events[0].EnergyAvailable != 99000 {
+		t.Errorf("energy available %d, want 99000", events[0].EnergyAvailable)
+	}
+}
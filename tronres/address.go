@@ -0,0 +1,166 @@
+package tronres
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	addressPrefix      = 0x41
+	addressPayloadSize = 25
+	checksumSize       = 4
+
+	hexAddressLength = 42
+)
+
+// IsHexAddress reports whether address looks like a hex-encoded TRON address (41...)
+func IsHexAddress(address string) bool {
+	return len(address) == hexAddressLength && strings.HasPrefix(address, "41")
+}
+
+// ValidateAddress checks if the given string is a valid TRON address.
+// Both base58 (T...) and hex (41...) formats are accepted.
+func ValidateAddress(address string) error {
+	if IsHexAddress(address) {
+		if _, err := hex.DecodeString(address); err != nil {
+			return fmt.Errorf("invalid hex address: %w", err)
+		}
+		return nil
+	}
+
+	if len(address) != 34 {
+		return fmt.Errorf("invalid address length: expected 34, got %d", len(address))
+	}
+	if !strings.HasPrefix(address, "T") {
+		return fmt.Errorf("invalid address format: must start with 'T'")
+	}
+
+	payload, err := decodeBase58(address)
+	if err != nil {
+		return fmt.Errorf("invalid address encoding: %w", err)
+	}
+	if len(payload) != addressPayloadSize {
+		return fmt.Errorf("invalid address length: decoded payload is %d bytes, expected %d", len(payload), addressPayloadSize)
+	}
+	if payload[0] != addressPrefix {
+		return fmt.Errorf("invalid address prefix: expected 0x%02x, got 0x%02x", addressPrefix, payload[0])
+	}
+
+	body := payload[:addressPayloadSize-checksumSize]
+	if !bytes.Equal(checksum(body), payload[addressPayloadSize-checksumSize:]) {
+		return fmt.Errorf("invalid address checksum: %s looks mistyped", address)
+	}
+
+	return nil
+}
+
+// NormalizeAddress validates address and returns it in base58 (T...) form
+func NormalizeAddress(address string) (string, error) {
+	if err := ValidateAddress(address); err != nil {
+		return "", err
+	}
+	if IsHexAddress(address) {
+		return ToBase58(address)
+	}
+	return address, nil
+}
+
+// ToHex converts a base58 address to its hex (41...) form.
+// Hex input is returned unchanged.
+func ToHex(address string) (string, error) {
+	if err := ValidateAddress(address); err != nil {
+		return "", err
+	}
+	if IsHexAddress(address) {
+		return strings.ToLower(address), nil
+	}
+
+	payload, err := decodeBase58(address)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(payload[:addressPayloadSize-checksumSize]), nil
+}
+
+// ToBase58 converts a hex (41...) address to its base58 form.
+// Base58 input is returned unchanged.
+func ToBase58(address string) (string, error) {
+	if err := ValidateAddress(address); err != nil {
+		return "", err
+	}
+	if !IsHexAddress(address) {
+		return address, nil
+	}
+
+	body, err := hex.DecodeString(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid hex address: %w", err)
+	}
+	return encodeBase58(append(body, checksum(body)...)), nil
+}
+
+// checksum returns the first 4 bytes of double SHA256 of data
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:checksumSize]
+}
+
+// decodeBase58 decodes a base58 (bitcoin alphabet) string into bytes
+func decodeBase58(s string) ([]byte, error) {
+	result := big.NewInt(0)
+	radix := big.NewInt(58)
+
+	for i, c := range s {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at position %d", c, i)
+		}
+		result.Mul(result, radix)
+		result.Add(result, big.NewInt(int64(idx)))
+	}
+
+	decoded := result.Bytes()
+
+	// Leading '1' characters encode leading zero bytes
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
+
+// encodeBase58 encodes bytes into a base58 (bitcoin alphabet) string
+func encodeBase58(data []byte) string {
+	num := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as '1'
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	// Reverse
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
@@ -0,0 +1,359 @@
+package tronres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout        = 5 * time.Second
+	apiKeyTimeout         = 10 * time.Second
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+
+	apiKeyHeader = "TRON-PRO-API-KEY"
+)
+
+// ClientOptions configures a Client. Zero values fall back to defaults.
+type ClientOptions struct {
+	// APIKey is sent as the TRON-PRO-API-KEY header when not empty.
+	// Self-hosted nodes ignore the header, so an empty or invalid key still works against them.
+	APIKey string
+	// Timeout is the per-request HTTP timeout (default 5s, 10s with an API key)
+	Timeout time.Duration
+	// MaxRetries is the number of attempts per request (default 3)
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, doubled on each attempt (default 100ms)
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff (default 5s)
+	MaxBackoff time.Duration
+	// FallbackNodes are tried in order when the primary node keeps failing
+	FallbackNodes []string
+	// Proxy is the HTTP, HTTPS or SOCKS5 proxy all requests go through.
+	// When nil, HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment apply.
+	Proxy *url.URL
+	// Solidity reads account resources from /walletsolidity, the confirmed
+	// state, instead of the full node's latest state. It lags about a minute.
+	Solidity bool
+	// MaxRPS caps the requests per second to all nodes together, retries
+	// included; requests over the budget wait (0 = unlimited)
+	MaxRPS float64
+}
+
+// ResourceClient is the part of the node API a Monitor polls. *Client
+// implements it; tests and dry runs can pass their own implementation.
+type ResourceClient interface {
+	GetAccountResource(ctx context.Context, address string) (*APIResponse, error)
+}
+
+// Client is an HTTP client for TRON API.
+// It holds an ordered list of nodes and sticks to the last one that answered.
+type Client struct {
+	nodeURLs       []string
+	apiKey         string
+	solidity       bool
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	httpClient     *http.Client
+	limiter        *rateLimiter // nil when unlimited
+
+	mu        sync.Mutex
+	current   int      // index of the currently healthy node
+	nodesUsed []string // nodes that served at least one response, in order
+}
+
+// NewClient creates a new TRON API client with default options
+func NewClient(nodeURL, apiKey string) *Client {
+	return NewClientWithOptions(nodeURL, ClientOptions{APIKey: apiKey})
+}
+
+// NewClientWithOptions creates a TRON API client with custom timeout and retry settings
+func NewClientWithOptions(nodeURL string, opts ClientOptions) *Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		// Keyed TronGrid requests are not throttled as hard, so we can afford
+		// to wait longer for a slow response instead of retrying
+		timeout = defaultTimeout
+		if opts.APIKey != "" {
+			timeout = apiKeyTimeout
+		}
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	initialBackoff := opts.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultInitialBackoff
+	}
+
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+
+	nodeURLs := []string{strings.TrimSuffix(nodeURL, "/")}
+	for _, fallback := range opts.FallbackNodes {
+		nodeURLs = append(nodeURLs, strings.TrimSuffix(fallback, "/"))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.Proxy != nil {
+		transport.Proxy = http.ProxyURL(opts.Proxy)
+	}
+
+	var limiter *rateLimiter
+	if opts.MaxRPS > 0 {
+		limiter = newRateLimiter(opts.MaxRPS)
+	}
+
+	return &Client{
+		nodeURLs:       nodeURLs,
+		apiKey:         opts.APIKey,
+		solidity:       opts.Solidity,
+		maxRetries:     maxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		limiter:        limiter,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// GetAccountResource fetches account resources from TRON API.
+// If the current node fails after all retries, the next node in the list is tried.
+// Cancelling ctx aborts the request in flight and any pending retry.
+func (c *Client) GetAccountResource(ctx context.Context, address string) (*APIResponse, error) {
+	var result APIResponse
+	if err := c.post(ctx, c.ResourceEndpoint(), addressPayload(address), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ResourceEndpoint returns the API path GetAccountResource requests
+func (c *Client) ResourceEndpoint() string {
+	if c.solidity {
+		return "/walletsolidity/getaccountresource"
+	}
+	return "/wallet/getaccountresource"
+}
+
+// GetAccount fetches the account's balance and staking state from TRON API
+func (c *Client) GetAccount(ctx context.Context, address string) (*AccountAPIResponse, error) {
+	var result AccountAPIResponse
+	if err := c.post(ctx, "/wallet/getaccount", addressPayload(address), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetChainParameters fetches network parameters such as resource prices
+func (c *Client) GetChainParameters(ctx context.Context) (*ChainParametersResponse, error) {
+	var result ChainParametersResponse
+	if err := c.post(ctx, "/wallet/getchainparameters", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SingleAttempt returns a client that sends each request once to the
+// currently healthy node, without retries or failover. It shares the HTTP
+// client and the MaxRPS budget with c.
+func (c *Client) SingleAttempt() *Client {
+	c.mu.Lock()
+	node := c.nodeURLs[c.current]
+	c.mu.Unlock()
+
+	return &Client{
+		nodeURLs:       []string{node},
+		apiKey:         c.apiKey,
+		solidity:       c.solidity,
+		maxRetries:     1,
+		initialBackoff: c.initialBackoff,
+		maxBackoff:     c.maxBackoff,
+		httpClient:     c.httpClient,
+		limiter:        c.limiter,
+	}
+}
+
+// NodesUsed returns the nodes that served at least one response, in order of first use
+func (c *Client) NodesUsed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.nodesUsed...)
+}
+
+// Throttled returns how many requests waited for the MaxRPS budget and how
+// long they waited in total
+func (c *Client) Throttled() (requests int, wait time.Duration) {
+	if c.limiter == nil {
+		return 0, 0
+	}
+	return c.limiter.stats()
+}
+
+func (c *Client) markHealthy(idx int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current = idx
+	for _, used := range c.nodesUsed {
+		if used == c.nodeURLs[idx] {
+			return
+		}
+	}
+	c.nodesUsed = append(c.nodesUsed, c.nodeURLs[idx])
+}
+
+// addressPayload builds the common {"address", "visible"} request body.
+// Hex addresses must be sent with visible=false, base58 with visible=true.
+func addressPayload(address string) map[string]interface{} {
+	return map[string]interface{}{
+		"address": address,
+		"visible": !IsHexAddress(address),
+	}
+}
+
+// post sends payload to path on the current node and decodes the response into out.
+// If the node fails after all retries, the next node in the list is tried.
+func (c *Client) post(ctx context.Context, path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	c.mu.Lock()
+	start := c.current
+	c.mu.Unlock()
+
+	var errs []error
+	for i := range c.nodeURLs {
+		idx := (start + i) % len(c.nodeURLs)
+		node := c.nodeURLs[idx]
+
+		err := c.requestWithRetry(ctx, node+path, body, out)
+		if err == nil {
+			c.markHealthy(idx)
+			return nil
+		}
+
+		// Cancelled by the caller, not a node failure
+		if ctx.Err() != nil {
+			return err
+		}
+
+		// The request itself is wrong, another node won't answer differently
+		if !IsRetryable(err) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", node, err))
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("all %d nodes failed: %w", len(c.nodeURLs), errors.Join(errs...))
+}
+
+func (c *Client) requestWithRetry(ctx context.Context, url string, body []byte, out interface{}) error {
+	var lastErr error
+	backoff := c.initialBackoff
+
+	for attempt := 1; attempt <= c.maxRetries; attempt++ {
+		err := c.doRequest(ctx, url, body, out)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		if !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt < c.maxRetries {
+			if err := sleepContext(ctx, backoff); err != nil {
+				return err
+			}
+			backoff = min(backoff*2, c.maxBackoff) // capped exponential backoff
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", c.maxRetries, lastErr)
+}
+
+func (c *Client) doRequest(ctx context.Context, url string, body []byte, out interface{}) error {
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if apiErr := parseAPIError(respBody); apiErr != nil {
+		return apiErr
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// sleepContext waits for d, returning early with ctx.Err() when ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+// Package tronres monitors the Energy and Bandwidth of TRON accounts and
+// analyzes how fast they regenerate and are consumed.
+//
+// A typical program creates a Client for a node, samples an address with a
+// Monitor and passes the snapshots to Analyze:
+//
+//	c := tronres.NewClient("https://api.trongrid.io", "")
+//	m := tronres.NewMonitorWithInterval(c, "TXxx...", 60, 1000)
+//	snapshots, err := m.Collect(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	analysis := tronres.Analyze(snapshots)
+//
+// Simulate projects the transaction capacity of the account from the last
+// snapshot and the analysis.
+package tronres
@@ -0,0 +1,86 @@
+package tronres
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// APIError is an error reported by the node inside an HTTP 200 response body,
+// e.g. {"Error":"account not found"} or {"code":"...","message":"..."}
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return "node error " + e.Code + ": " + e.Message
+	}
+	return "node error: " + e.Message
+}
+
+// NotFound reports whether the node says the account does not exist
+func (e *APIError) NotFound() bool {
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "not exist") ||
+		strings.Contains(msg, "not activated")
+}
+
+// Retryable reports whether repeating the request may succeed.
+// Errors about the account or address itself will not go away on retry.
+func (e *APIError) Retryable() bool {
+	if e.NotFound() {
+		return false
+	}
+	msg := strings.ToLower(e.Message)
+	return !strings.Contains(msg, "invalid address")
+}
+
+// IsRetryable reports whether err is worth retrying.
+// Anything that is not an APIError (network errors, bad status codes) is retryable.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// parseAPIError returns an APIError if body is an error-shaped response
+func parseAPIError(body []byte) *APIError {
+	var probe struct {
+		Error   string `json:"Error"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil
+	}
+
+	if probe.Error != "" {
+		return &APIError{Message: probe.Error}
+	}
+	if probe.Code != "" || probe.Message != "" {
+		return &APIError{Code: probe.Code, Message: decodeMessage(probe.Message)}
+	}
+
+	return nil
+}
+
+// decodeMessage decodes hex-encoded messages that some node versions return
+func decodeMessage(msg string) string {
+	decoded, err := hex.DecodeString(msg)
+	if err != nil || len(decoded) == 0 {
+		return msg
+	}
+	for _, r := range string(decoded) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return msg
+		}
+	}
+	return string(decoded)
+}
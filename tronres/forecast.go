@@ -0,0 +1,41 @@
+package tronres
+
+import (
+	"math"
+	"time"
+)
+
+// ForecastEnergy estimates when the account will have targetEnergy available,
+// starting from snapshot and growing at the measured net energy rate.
+// Available energy can't grow past the limit, so a higher target is capped
+// at the limit and reported with Capped set.
+func ForecastEnergy(snapshot Snapshot, analysis Analysis, targetEnergy int64) Forecast {
+	f := Forecast{
+		TargetEnergy:    targetEnergy,
+		CurrentEnergy:   snapshot.EnergyAvailable,
+		EnergyLimit:     snapshot.EnergyLimit,
+		NetRatePerSec:   analysis.EnergyNetRatePerSec,
+		FromTime:        snapshot.Timestamp,
+		ReachableTarget: targetEnergy,
+	}
+
+	if f.TargetEnergy > f.EnergyLimit {
+		f.ReachableTarget = f.EnergyLimit
+		f.Capped = true
+	}
+
+	missing := f.ReachableTarget - f.CurrentEnergy
+	switch {
+	case missing <= 0:
+		f.Reachable = true
+	case f.NetRatePerSec > 0:
+		f.Reachable = true
+		f.Seconds = float64(missing) / f.NetRatePerSec
+	default:
+		// Zero or negative net rate: the buffer never grows
+		return f
+	}
+
+	f.ETA = f.FromTime.Add(time.Duration(math.Round(f.Seconds * float64(time.Second))))
+	return f
+}
@@ -0,0 +1,618 @@
+package tronres
+
+import "time"
+
+// Snapshot represents a single measurement of TRON account resources
+type Snapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+
+	// Energy
+	EnergyLimit int64 `json:"energy_limit"`
+	EnergyUsed  int64 `json:"energy_used"`
+
+	// Bandwidth
+	NetLimit     int64 `json:"net_limit"`
+	NetUsed      int64 `json:"net_used"`
+	FreeNetLimit int64 `json:"free_net_limit"`
+	FreeNetUsed  int64 `json:"free_net_used"`
+
+	// Network totals the staked limits are a share of; the weights are the
+	// TRX staked for each resource network-wide. Zero in older reports.
+	TotalEnergyLimit  int64 `json:"total_energy_limit,omitempty"`
+	TotalEnergyWeight int64 `json:"total_energy_weight,omitempty"`
+	TotalNetLimit     int64 `json:"total_net_limit,omitempty"`
+	TotalNetWeight    int64 `json:"total_net_weight,omitempty"`
+
+	// Computed
+	EnergyAvailable    int64 `json:"energy_available"`
+	BandwidthAvailable int64 `json:"bandwidth_available"`
+
+	// Bandwidth split by source (BandwidthAvailable = staked + free)
+	StakedBandwidthAvailable int64 `json:"staked_bandwidth_available"`
+	FreeBandwidthAvailable   int64 `json:"free_bandwidth_available"`
+
+	// Deltas from previous snapshot
+	DeltaEnergy    int64 `json:"delta_energy"`
+	DeltaBandwidth int64 `json:"delta_bandwidth"`
+
+	DeltaStakedBandwidth int64 `json:"delta_staked_bandwidth"`
+	DeltaFreeBandwidth   int64 `json:"delta_free_bandwidth"`
+
+	// ResumeGap marks the first snapshot after resuming a previous session.
+	// Its deltas span the pause and are not counted in the analysis.
+	ResumeGap bool `json:"resume_gap,omitempty"`
+
+	// ClockAnomaly marks a snapshot whose wall clock moved ClockSkewMs more
+	// (or less, when negative) than the monotonic clock since the previous
+	// one, e.g. an NTP step or a sleep/wake. Its deltas are not counted.
+	ClockAnomaly bool  `json:"clock_anomaly,omitempty"`
+	ClockSkewMs  int64 `json:"clock_skew_ms,omitempty"`
+
+	// Inactive marks a response with all resource limits zero, as the node
+	// returns for an account that has not been activated yet
+	Inactive bool `json:"inactive,omitempty"`
+
+	// outlier marks a delta rejected by AnalyzeOptions.FilterOutliers, set on the analysis' own copy
+	outlier bool
+
+	// Recovery is the progress towards full resources since the first
+	// snapshot of the run, set by RunUntilFull only
+	Recovery *RecoveryProgress `json:"-"`
+
+	// Failed marks a placeholder passed to snapshot callbacks when a poll failed.
+	// Failed snapshots are never part of the collected data.
+	Failed bool `json:"-"`
+}
+
+// RecoveryProgress is how far an account has recovered since a baseline
+// snapshot: the share of the energy and bandwidth used at the baseline that
+// has regenerated since, and the time until nothing is used at the running
+// rate of recovery
+type RecoveryProgress struct {
+	EnergyPercent    float64
+	BandwidthPercent float64
+	// ETA is negative while it is unknown, i.e. no recovery was seen yet
+	ETA time.Duration
+}
+
+// TotalBandwidthLimit returns total bandwidth limit (staked + free)
+func (s *Snapshot) TotalBandwidthLimit() int64 {
+	return s.NetLimit + s.FreeNetLimit
+}
+
+// TotalBandwidthUsed returns total bandwidth used (staked + free)
+func (s *Snapshot) TotalBandwidthUsed() int64 {
+	return s.NetUsed + s.FreeNetUsed
+}
+
+// APIResponse represents the response from TRON getaccountresource API
+type APIResponse struct {
+	FreeNetLimit      int64 `json:"freeNetLimit"`
+	FreeNetUsed       int64 `json:"freeNetUsed"`
+	NetLimit          int64 `json:"NetLimit"`
+	NetUsed           int64 `json:"NetUsed"`
+	EnergyLimit       int64 `json:"EnergyLimit"`
+	EnergyUsed        int64 `json:"EnergyUsed"`
+	TotalNetLimit     int64 `json:"TotalNetLimit"`
+	TotalNetWeight    int64 `json:"TotalNetWeight"`
+	TotalEnergyLimit  int64 `json:"TotalEnergyLimit"`
+	TotalEnergyWeight int64 `json:"TotalEnergyWeight"`
+}
+
+// AccountAPIResponse represents the relevant part of the TRON getaccount API response.
+// All amounts are in sun (1 TRX = 1,000,000 sun).
+type AccountAPIResponse struct {
+	Balance  int64 `json:"balance"`
+	FrozenV2 []struct {
+		Type   string `json:"type"` // empty means BANDWIDTH
+		Amount int64  `json:"amount"`
+	} `json:"frozenV2"`
+	DelegatedFrozenV2BalanceForBandwidth         int64 `json:"delegated_frozenV2_balance_for_bandwidth"`
+	AcquiredDelegatedFrozenV2BalanceForBandwidth int64 `json:"acquired_delegated_frozenV2_balance_for_bandwidth"`
+	AccountResource                              struct {
+		DelegatedFrozenV2BalanceForEnergy         int64 `json:"delegated_frozenV2_balance_for_energy"`
+		AcquiredDelegatedFrozenV2BalanceForEnergy int64 `json:"acquired_delegated_frozenV2_balance_for_energy"`
+	} `json:"account_resource"`
+}
+
+// AccountInfo converts the raw response into an AccountInfo
+func (r *AccountAPIResponse) AccountInfo() AccountInfo {
+	info := AccountInfo{
+		BalanceSun:               r.Balance,
+		DelegatedOutEnergySun:    r.AccountResource.DelegatedFrozenV2BalanceForEnergy,
+		DelegatedOutBandwidthSun: r.DelegatedFrozenV2BalanceForBandwidth,
+		AcquiredEnergySun:        r.AccountResource.AcquiredDelegatedFrozenV2BalanceForEnergy,
+		AcquiredBandwidthSun:     r.AcquiredDelegatedFrozenV2BalanceForBandwidth,
+	}
+
+	for _, frozen := range r.FrozenV2 {
+		switch frozen.Type {
+		case "", "BANDWIDTH":
+			info.StakedBandwidthSun += frozen.Amount
+		case "ENERGY":
+			info.StakedEnergySun += frozen.Amount
+		}
+	}
+
+	return info
+}
+
+// AccountInfo contains balance and staking state of the account, in sun
+type AccountInfo struct {
+	BalanceSun               int64 `json:"balance_sun"`
+	StakedEnergySun          int64 `json:"staked_energy_sun"`
+	StakedBandwidthSun       int64 `json:"staked_bandwidth_sun"`
+	DelegatedOutEnergySun    int64 `json:"delegated_out_energy_sun"`
+	DelegatedOutBandwidthSun int64 `json:"delegated_out_bandwidth_sun"`
+	AcquiredEnergySun        int64 `json:"acquired_energy_sun"`
+	AcquiredBandwidthSun     int64 `json:"acquired_bandwidth_sun"`
+}
+
+// TotalStakedEnergySun returns the TRX staked for energy including the part delegated to others
+func (a *AccountInfo) TotalStakedEnergySun() int64 {
+	return a.StakedEnergySun + a.DelegatedOutEnergySun
+}
+
+// TotalStakedBandwidthSun returns the TRX staked for bandwidth including the part delegated to others
+func (a *AccountInfo) TotalStakedBandwidthSun() int64 {
+	return a.StakedBandwidthSun + a.DelegatedOutBandwidthSun
+}
+
+// LimitBreakdown splits the energy and staked bandwidth limits of s into the
+// part backed by the account's own stake and the part delegated in by other
+// accounts, in proportion to the TRX behind each
+func (a *AccountInfo) LimitBreakdown(s Snapshot) ResourceBreakdown {
+	b := ResourceBreakdown{
+		EnergyLimit:    s.EnergyLimit,
+		BandwidthLimit: s.NetLimit,
+	}
+	b.EnergyDelegatedIn = delegatedShare(s.EnergyLimit, a.StakedEnergySun, a.AcquiredEnergySun)
+	b.EnergyFromStake = s.EnergyLimit - b.EnergyDelegatedIn
+	b.BandwidthDelegatedIn = delegatedShare(s.NetLimit, a.StakedBandwidthSun, a.AcquiredBandwidthSun)
+	b.BandwidthFromStake = s.NetLimit - b.BandwidthDelegatedIn
+	return b
+}
+
+// delegatedShare returns the part of limit backed by acquired out of own+acquired sun
+func delegatedShare(limit, ownSun, acquiredSun int64) int64 {
+	if acquiredSun <= 0 {
+		return 0
+	}
+	return int64(float64(limit) * float64(acquiredSun) / float64(ownSun+acquiredSun))
+}
+
+// ResourceBreakdown splits resource limits by where they come from. The
+// delegated-in part can be reclaimed by the delegating accounts at any time.
+type ResourceBreakdown struct {
+	EnergyLimit       int64 `json:"energy_limit"`
+	EnergyFromStake   int64 `json:"energy_from_stake"`
+	EnergyDelegatedIn int64 `json:"energy_delegated_in"`
+
+	// Staked bandwidth only, the free daily allowance is neither
+	BandwidthLimit       int64 `json:"bandwidth_limit"`
+	BandwidthFromStake   int64 `json:"bandwidth_from_stake"`
+	BandwidthDelegatedIn int64 `json:"bandwidth_delegated_in"`
+}
+
+// Resources a RecoveryTarget can wait for
+const (
+	RecoverBoth      = "both"
+	RecoverEnergy    = "energy"
+	RecoverBandwidth = "bandwidth"
+)
+
+// RecoveryTarget defines when RunUntilFull considers an account recovered.
+// The zero value waits until no energy and no bandwidth is used.
+type RecoveryTarget struct {
+	// Resource is the one that has to recover: RecoverBoth (default),
+	// RecoverEnergy or RecoverBandwidth
+	Resource string
+	// ThresholdPercent counts a resource as recovered once at most this
+	// share of its limit is used, e.g. 1 for 1% (0 = fully unused)
+	ThresholdPercent float64
+}
+
+// Reached reports whether s meets the target
+func (t RecoveryTarget) Reached(s Snapshot) bool {
+	energy := s.EnergyUsed <= t.usedGoal(s.EnergyLimit)
+	bandwidth := s.TotalBandwidthUsed() <= t.usedGoal(s.TotalBandwidthLimit())
+
+	switch t.Resource {
+	case RecoverEnergy:
+		return energy
+	case RecoverBandwidth:
+		return bandwidth
+	default:
+		return energy && bandwidth
+	}
+}
+
+// usedGoal returns the most of limit that may be used for the target
+func (t RecoveryTarget) usedGoal(limit int64) int64 {
+	if t.ThresholdPercent <= 0 {
+		return 0
+	}
+	return int64(t.ThresholdPercent / 100 * float64(limit))
+}
+
+// Metadata contains information about the monitoring session
+type Metadata struct {
+	Address         string    `json:"address"`
+	Node            string    `json:"node"`
+	NodesUsed       []string  `json:"nodes_used,omitempty"`
+	Endpoint        string    `json:"endpoint,omitempty"` // API path of the resource polls
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationSeconds int       `json:"duration_seconds"`
+	SamplesCount    int       `json:"samples_count"`
+	IntervalMs      int       `json:"interval_ms"`
+
+	// Polls made and how many of them returned no data (omitted when unknown or none)
+	AttemptedSamples int `json:"attempted_samples,omitempty"`
+	FailedSamples    int `json:"failed_samples,omitempty"`
+
+	// Requests of the whole run that waited for the --max-rps budget, and
+	// the total wait (omitted when none)
+	ThrottledRequests int   `json:"throttled_requests,omitempty"`
+	ThrottleWaitMs    int64 `json:"throttle_wait_ms,omitempty"`
+
+	// Snapshots taken across a jump of the system clock (omitted when none)
+	ClockAnomalies int `json:"clock_anomalies,omitempty"`
+
+	// Actual spacing between consecutive samples
+	ActualIntervalMeanMs   float64 `json:"actual_interval_mean_ms"`
+	ActualIntervalStddevMs float64 `json:"actual_interval_stddev_ms"`
+}
+
+// TickAnalysis contains block tick detection results
+type TickAnalysis struct {
+	// Recovery ticks (positive deltas)
+	RecoveryTicks       int     `json:"recovery_ticks"`
+	AvgRecoveryInterval float64 `json:"avg_recovery_interval_sec"`
+	EnergyPerTick       float64 `json:"energy_per_tick"`
+	BandwidthPerTick    float64 `json:"bandwidth_per_tick"`
+	RecoveryTicksPerHr  float64 `json:"recovery_ticks_per_hour"`
+	RecoveryTicksPerDay float64 `json:"recovery_ticks_per_day"`
+
+	// Robust spread of recovery ticks, less distorted by a single long gap than the averages
+	MedianRecoveryInterval float64 `json:"median_recovery_interval_sec"`
+	P90RecoveryInterval    float64 `json:"p90_recovery_interval_sec"`
+	P95RecoveryInterval    float64 `json:"p95_recovery_interval_sec"`
+	MedianEnergyPerTick    float64 `json:"median_energy_per_tick"`
+	P90EnergyPerTick       float64 `json:"p90_energy_per_tick"`
+	P95EnergyPerTick       float64 `json:"p95_energy_per_tick"`
+
+	// Jitter of recovery ticks: standard deviation and coefficient of variation
+	// (stddev / mean). A high CV means ticks arrive bunched, a sign that the
+	// sampling interval is coarse for the 3 second block time.
+	EnergyPerTickStddev    float64 `json:"energy_per_tick_stddev"`
+	EnergyPerTickCV        float64 `json:"energy_per_tick_cv"`
+	RecoveryIntervalStddev float64 `json:"recovery_interval_stddev_sec"`
+	RecoveryIntervalCV     float64 `json:"recovery_interval_cv"`
+
+	// Consumption events (negative deltas)
+	ConsumptionEvents      int     `json:"consumption_events"`
+	TotalEnergyConsumed    int64   `json:"total_energy_consumed"`
+	TotalBandwidthConsumed int64   `json:"total_bandwidth_consumed"`
+	AvgEnergyPerConsume    float64 `json:"avg_energy_per_consumption"`
+	AvgBandwidthPerConsume float64 `json:"avg_bandwidth_per_consumption"`
+
+	// Raw data
+	TickTimestampsMs    []int64 `json:"tick_timestamps_ms"`
+	TickEnergyDeltas    []int64 `json:"tick_energy_deltas"`
+	TickBandwidthDeltas []int64 `json:"tick_bandwidth_deltas"`
+}
+
+// UsedBasedAnalysis contains analysis based on resources used
+type UsedBasedAnalysis struct {
+	EnergyUsedRatio                float64 `json:"energy_used_ratio"`
+	BandwidthUsedRatio             float64 `json:"bandwidth_used_ratio"`
+	EnergyUsedAtStart              int64   `json:"energy_used_at_start"`
+	BandwidthUsedAtStart           int64   `json:"bandwidth_used_at_start"`
+	EstimatedFullRecoverySeconds   float64 `json:"estimated_full_recovery_seconds"`
+	EstimatedFullRecoveryHours     float64 `json:"estimated_full_recovery_hours"`
+	EnergyRecoveryMatchesUsedModel bool    `json:"energy_recovery_matches_used_model"`
+	MeasuredRecoveryRate           float64 `json:"measured_recovery_rate_per_sec"`
+	UsedBasedRecoveryRate          float64 `json:"used_based_recovery_rate_per_sec"`
+}
+
+// FormulaValidation contains model comparison
+type FormulaValidation struct {
+	TheoreticalModel string  `json:"theoretical_model"`
+	MeasuredModel    string  `json:"measured_model"`
+	BestFit          string  `json:"best_fit"`
+	Confidence       float64 `json:"confidence"`
+}
+
+// RegressionAnalysis contains a least-squares fit of energy_available over
+// time within recovery segments (between consumption events)
+type RegressionAnalysis struct {
+	SlopePerSec float64 `json:"slope_per_sec"`
+	SlopePerDay float64 `json:"slope_per_day"`
+	RSquared    float64 `json:"r_squared"`
+	StdError    float64 `json:"std_error_per_sec"`
+	Samples     int     `json:"samples"`
+	Segments    int     `json:"segments"`
+}
+
+// PracticalEstimates contains transaction capacity estimates
+type PracticalEstimates struct {
+	TxPerDay65kWithBuffer  float64 `json:"tx_per_day_65k_with_buffer"`
+	TxPerDay65kSustained   float64 `json:"tx_per_day_65k_sustained"`
+	TxPerDay131kWithBuffer float64 `json:"tx_per_day_131k_with_buffer"`
+	TxPerDay131kSustained  float64 `json:"tx_per_day_131k_sustained"`
+	EnergyNeeded800Tx65k   int64   `json:"energy_needed_for_800_tx_65k"`
+	EnergyNeeded800Tx131k  int64   `json:"energy_needed_for_800_tx_131k"`
+	ImmediateCapacity65k   int64   `json:"immediate_capacity_65k"`
+	ImmediateCapacity131k  int64   `json:"immediate_capacity_131k"`
+
+	// Bandwidth capacity for a plain TRX transfer, by source
+	TxPerDayTransferStaked float64 `json:"tx_per_day_transfer_staked_bandwidth"`
+	TxPerDayTransferFree   float64 `json:"tx_per_day_transfer_free_bandwidth"`
+	TxPerDayTransfer       float64 `json:"tx_per_day_transfer_bandwidth"`
+
+	// TRX that would be burned to cover the observed consumption without resources
+	TrxBurnedEstimate       float64 `json:"trx_burned_estimate"`
+	TrxBurnedPerDayEstimate float64 `json:"trx_burned_per_day_estimate"`
+}
+
+// ResourcePrices holds the on-chain price of resources burned with TRX, in sun per unit
+type ResourcePrices struct {
+	EnergyFeeSun    int64 `json:"energy_fee_sun"`
+	BandwidthFeeSun int64 `json:"bandwidth_fee_sun"`
+}
+
+// BurnTRX returns the TRX burned to pay for the given amount of energy and bandwidth
+func (p ResourcePrices) BurnTRX(energy, bandwidth int64) float64 {
+	return float64(energy*p.EnergyFeeSun+bandwidth*p.BandwidthFeeSun) / 1_000_000
+}
+
+// ChainParametersResponse represents the response from TRON getchainparameters API
+type ChainParametersResponse struct {
+	ChainParameter []struct {
+		Key   string `json:"key"`
+		Value int64  `json:"value"`
+	} `json:"chainParameter"`
+}
+
+// Value returns the named chain parameter and whether it was present
+func (r *ChainParametersResponse) Value(key string) (int64, bool) {
+	for _, p := range r.ChainParameter {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Analysis contains calculated statistics from the monitoring session
+type Analysis struct {
+	// Timing
+	ActualDurationSec float64 `json:"actual_duration_seconds"`
+
+	// Energy stats
+	EnergyStart      int64 `json:"energy_start"`
+	EnergyEnd        int64 `json:"energy_end"`
+	EnergyTotalDelta int64 `json:"energy_total_delta"`
+
+	// Separated rates for Energy
+	EnergyRegenerated       int64   `json:"energy_regenerated"`
+	EnergyConsumed          int64   `json:"energy_consumed"`
+	EnergyRegenRatePerSec   float64 `json:"energy_regen_rate_per_second"`
+	EnergyRegenRatePerDay   float64 `json:"energy_regen_rate_per_day"`
+	EnergyConsumeRatePerSec float64 `json:"energy_consume_rate_per_second"`
+	EnergyConsumeRatePerDay float64 `json:"energy_consume_rate_per_day"`
+	EnergyNetRatePerSec     float64 `json:"energy_net_rate_per_second"`
+	EnergyNetRatePerDay     float64 `json:"energy_net_rate_per_day"`
+
+	// Bandwidth stats
+	BandwidthStart      int64 `json:"bandwidth_start"`
+	BandwidthEnd        int64 `json:"bandwidth_end"`
+	BandwidthTotalDelta int64 `json:"bandwidth_total_delta"`
+
+	// Separated rates for Bandwidth
+	BandwidthRegenerated       int64   `json:"bandwidth_regenerated"`
+	BandwidthConsumed          int64   `json:"bandwidth_consumed"`
+	BandwidthRegenRatePerSec   float64 `json:"bandwidth_regen_rate_per_second"`
+	BandwidthRegenRatePerDay   float64 `json:"bandwidth_regen_rate_per_day"`
+	BandwidthConsumeRatePerSec float64 `json:"bandwidth_consume_rate_per_second"`
+	BandwidthConsumeRatePerDay float64 `json:"bandwidth_consume_rate_per_day"`
+	BandwidthNetRatePerSec     float64 `json:"bandwidth_net_rate_per_second"`
+	BandwidthNetRatePerDay     float64 `json:"bandwidth_net_rate_per_day"`
+
+	// Bandwidth split by source
+	StakedBandwidthRegenerated     int64   `json:"staked_bandwidth_regenerated"`
+	StakedBandwidthConsumed        int64   `json:"staked_bandwidth_consumed"`
+	StakedBandwidthRegenRatePerDay float64 `json:"staked_bandwidth_regen_rate_per_day"`
+	FreeBandwidthRegenerated       int64   `json:"free_bandwidth_regenerated"`
+	FreeBandwidthConsumed          int64   `json:"free_bandwidth_consumed"`
+	FreeBandwidthRegenRatePerDay   float64 `json:"free_bandwidth_regen_rate_per_day"`
+
+	// Range of available resources across all snapshots
+	EnergyAvailableStats    ResourceStats `json:"energy_available_stats"`
+	BandwidthAvailableStats ResourceStats `json:"bandwidth_available_stats"`
+
+	// Theoretical rates
+	TheoreticalEnergyRatePerDay    float64 `json:"theoretical_energy_rate_per_day"`
+	TheoreticalBandwidthRatePerDay float64 `json:"theoretical_bandwidth_rate_per_day"`
+	EnergyRateMatchesTheory        bool    `json:"energy_rate_matches_theory"`
+	BandwidthRateMatchesTheory     bool    `json:"bandwidth_rate_matches_theory"`
+
+	// Share of the network totals at the last snapshot: the account's part of
+	// the total limit, and the limit one staked TRX currently gets. Zero when
+	// the node didn't report the totals.
+	EnergyShareRatio      float64 `json:"energy_share_ratio,omitempty"`
+	BandwidthShareRatio   float64 `json:"bandwidth_share_ratio,omitempty"`
+	EnergyPerStakedTRX    float64 `json:"energy_per_staked_trx,omitempty"`
+	BandwidthPerStakedTRX float64 `json:"bandwidth_per_staked_trx,omitempty"`
+
+	// Transaction estimates (based on regen rate)
+	TxPerDay65k  float64 `json:"tx_per_day_65k_energy"`
+	TxPerDay131k float64 `json:"tx_per_day_131k_energy"`
+
+	// Extended analysis
+	TickAnalysis       TickAnalysis       `json:"tick_analysis"`
+	RegressionAnalysis RegressionAnalysis `json:"regression_analysis"`
+	UsedBasedAnalysis  UsedBasedAnalysis  `json:"used_based_analysis"`
+	FormulaValidation  FormulaValidation  `json:"formula_validation"`
+	PracticalEstimates PracticalEstimates `json:"practical_estimates"`
+
+	// Limit changes (stake/unstake/delegation) seen during the session.
+	// The delta at each change is excluded from the totals and rates.
+	LimitChangeEvents []LimitChangeEvent `json:"limit_change_events,omitempty"`
+
+	// Outlier filtering (AnalyzeOptions.FilterOutliers): deltas rejected as spikes
+	// are excluded from the totals and rates like limit changes
+	OutlierFiltering bool `json:"outlier_filtering"`
+	OutliersRejected int  `json:"outliers_rejected"`
+
+	// SampleSuccessRate is the share of polls that returned data, 0 when the
+	// number of polls is unknown (AnalyzeOptions.AttemptedSamples)
+	SampleSuccessRate float64 `json:"sample_success_rate,omitempty"`
+
+	// RateWindows are the regeneration rates over successive windows of
+	// AnalyzeOptions.Window, empty when no window is set
+	RateWindows []RateWindow `json:"rate_windows,omitempty"`
+
+	// ResourceBreakdown splits the limits of the last snapshot into own stake
+	// and delegated-in resources; nil when the account info is unavailable
+	ResourceBreakdown *ResourceBreakdown `json:"resource_breakdown,omitempty"`
+
+	// AccountInactive is set when the last snapshot has all resource limits
+	// zero, i.e. the account appears not to be activated
+	AccountInactive bool `json:"account_inactive,omitempty"`
+
+	// Warnings about data quality that may affect the numbers above
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// RateWindow is the regeneration rate over one window of a session. A
+// delta counts towards the window its interval ends in; the last window ends
+// at the last snapshot and may be shorter.
+type RateWindow struct {
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	Samples     int       `json:"samples"`
+
+	// Rates per second over the counted intervals of the window
+	EnergyRegenRate    float64 `json:"energy_regen_rate"`
+	BandwidthRegenRate float64 `json:"bandwidth_regen_rate"`
+}
+
+// Resources reported in LimitChangeEvent
+const (
+	ResourceEnergy    = "energy"
+	ResourceBandwidth = "bandwidth"
+)
+
+// LimitChangeEvent records a jump of the energy or bandwidth limit between two snapshots
+type LimitChangeEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	ElapsedMs int64     `json:"elapsed_ms"`
+	Resource  string    `json:"resource"`
+	OldLimit  int64     `json:"old_limit"`
+	NewLimit  int64     `json:"new_limit"`
+}
+
+// ResourceStats summarizes the values a resource took during a session.
+// Stddev is the population standard deviation, 0 for a single snapshot.
+type ResourceStats struct {
+	Min    int64   `json:"min"`
+	Max    int64   `json:"max"`
+	Mean   float64 `json:"mean"`
+	Stddev float64 `json:"stddev"`
+}
+
+// MonitorReport is the complete output structure for JSON export
+type MonitorReport struct {
+	Metadata  Metadata     `json:"metadata"`
+	Account   *AccountInfo `json:"account,omitempty"`
+	Snapshots []Snapshot   `json:"snapshots"`
+	Analysis  Analysis     `json:"analysis,omitzero"` // omitted by a single snapshot, which isn't analysed
+}
+
+// SimulationResult contains transaction simulation output
+type SimulationResult struct {
+	TargetTx            int     `json:"target_tx"`
+	TxCost              int64   `json:"tx_cost_energy"`
+	CurrentAvailable    int64   `json:"current_available_energy"`
+	ImmediateCapacity   int64   `json:"immediate_capacity"`
+	RecoveryRatePerSec  float64 `json:"recovery_rate_per_sec"`
+	SecondsPerTx        float64 `json:"seconds_per_tx"`
+	Total24hCapacity    int64   `json:"total_24h_capacity"`
+	CanReachTarget      bool    `json:"can_reach_target"`
+	RequiredEnergyLimit int64   `json:"required_energy_limit_for_target"`
+	HourlyProjection    []int64 `json:"hourly_projection"`
+
+	// Energy delegated in by other accounts and the 24h capacity left without it
+	DelegatedInEnergy   int64 `json:"delegated_in_energy,omitempty"`
+	OwnStake24hCapacity int64 `json:"own_stake_24h_capacity,omitempty"`
+
+	// Bandwidth simulation, present when a bandwidth cost was given
+	Bandwidth *BandwidthSimulation `json:"bandwidth,omitempty"`
+
+	// Verdict across both resources
+	BindingConstraint string `json:"binding_constraint"`
+	EffectiveCapacity int64  `json:"effective_24h_capacity"`
+
+	// TRX burned per day to cover the part of the target the resources can't
+	Prices             ResourcePrices `json:"prices"`
+	TrxBurnedEstimate  float64        `json:"trx_burned_estimate"`
+	EnergyShortfall    int64          `json:"energy_shortfall_per_day"`
+	BandwidthShortfall int64          `json:"bandwidth_shortfall_per_day"`
+
+	// Breakdown by transaction type when simulating a mix (SimulateOptions.TxMix)
+	TxMix []TxMixResult `json:"tx_mix,omitempty"`
+}
+
+// TxCostShare is one transaction type of a simulated mix
+type TxCostShare struct {
+	Cost   int64   `json:"cost_energy"`
+	Weight float64 `json:"weight"`
+}
+
+// TxMixResult is how many transactions of one type of a mix fit per day
+type TxMixResult struct {
+	Cost   int64   `json:"cost_energy"`
+	Weight float64 `json:"weight"` // normalized, the weights of a mix sum to 1
+
+	// Total24hCapacity is this type's share of the blended 24h capacity
+	Total24hCapacity int64 `json:"total_24h_capacity"`
+	// AloneCapacity is the 24h energy capacity if every transaction were of this type (0 for energy-free types)
+	AloneCapacity int64 `json:"alone_24h_capacity"`
+}
+
+// Forecast is the estimated time until the account has a given amount of energy available
+type Forecast struct {
+	TargetEnergy    int64     `json:"target_energy"`
+	ReachableTarget int64     `json:"reachable_target_energy"` // target capped at the energy limit
+	Capped          bool      `json:"capped_at_limit"`
+	CurrentEnergy   int64     `json:"current_energy"`
+	EnergyLimit     int64     `json:"energy_limit"`
+	NetRatePerSec   float64   `json:"net_rate_per_sec"`
+	Reachable       bool      `json:"reachable"` // false when the net rate is zero or negative
+	Seconds         float64   `json:"seconds"`
+	FromTime        time.Time `json:"from_time"`
+	ETA             time.Time `json:"eta"`
+}
+
+// BandwidthSimulation contains the bandwidth side of a transaction simulation
+type BandwidthSimulation struct {
+	TxCost                 int64   `json:"tx_cost_bandwidth"`
+	CurrentAvailable       int64   `json:"current_available_bandwidth"`
+	StakedAvailable        int64   `json:"current_available_staked_bandwidth"`
+	FreeAvailable          int64   `json:"current_available_free_bandwidth"`
+	ImmediateCapacity      int64   `json:"immediate_capacity"`
+	ImmediateFromStaked    int64   `json:"immediate_capacity_staked"`
+	ImmediateFromFree      int64   `json:"immediate_capacity_free"`
+	RecoveryRatePerSec     float64 `json:"recovery_rate_per_sec"`
+	SecondsPerTx           float64 `json:"seconds_per_tx"`
+	SustainedTxPerDay      float64 `json:"sustained_tx_per_day"`
+	SustainedFromStaked    float64 `json:"sustained_tx_per_day_staked"`
+	SustainedFromFree      float64 `json:"sustained_tx_per_day_free"`
+	Total24hCapacity       int64   `json:"total_24h_capacity"`
+	CanReachTarget         bool    `json:"can_reach_target"`
+	RequiredBandwidthLimit int64   `json:"required_bandwidth_limit_for_target"`
+	HourlyProjection       []int64 `json:"hourly_projection"`
+}
@@ -0,0 +1,988 @@
+package tronres
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// TransferBandwidthCost is the typical bandwidth cost of a plain TRX transfer
+const TransferBandwidthCost = 268
+
+// FreeBandwidthPerDay is the free bandwidth every activated account gets per day
+const FreeBandwidthPerDay = 600
+
+// Monitor handles the resource monitoring logic
+type Monitor struct {
+	client     ResourceClient
+	address    string
+	duration   int
+	intervalMs int
+	resume     *Snapshot // last snapshot of a previous session, see ResumeFrom
+	target     RecoveryTarget
+	discard    bool // see DiscardSnapshots
+
+	// Polls made by all runs and how many of them returned no data
+	attempted atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewMonitor creates a new Monitor instance
+func NewMonitor(c ResourceClient, address string, duration int) *Monitor {
+	return &Monitor{
+		client:     c,
+		address:    address,
+		duration:   duration,
+		intervalMs: 1000,
+	}
+}
+
+// NewMonitorWithInterval creates a Monitor with custom interval
+func NewMonitorWithInterval(c ResourceClient, address string, duration, intervalMs int) *Monitor {
+	return &Monitor{
+		client:     c,
+		address:    address,
+		duration:   duration,
+		intervalMs: intervalMs,
+	}
+}
+
+// ResumeFrom continues a previous session that ended with last. The next
+// run keeps its elapsed time line and computes the first deltas against last.
+// The first new snapshot is marked with ResumeGap, its delta spans the
+// pause between the sessions and is left out of the analysis.
+func (m *Monitor) ResumeFrom(last Snapshot) {
+	m.resume = &last
+}
+
+// DiscardSnapshots makes Run and RunUntilFull hand the snapshots to the
+// callback only and return none, for callers that store them elsewhere and
+// would otherwise hold a long run in memory twice
+func (m *Monitor) DiscardSnapshots() {
+	m.discard = true
+}
+
+// SetRecoveryTarget changes when RunUntilFull considers the account
+// recovered. By default it waits until no energy and no bandwidth is used.
+func (m *Monitor) SetRecoveryTarget(target RecoveryTarget) {
+	m.target = target
+}
+
+// PollCounts returns the number of polls the runs of the monitor made so far
+// and how many of them returned no data. A poll counts once however often the
+// client retried it; one aborted by cancellation is not counted. It is safe
+// to call while a run is in progress.
+func (m *Monitor) PollCounts() (attempted, failed int) {
+	return int(m.attempted.Load()), int(m.failed.Load())
+}
+
+// Collect samples the account for the configured duration and returns the
+// snapshots. When ctx is cancelled it returns the snapshots taken so far
+// together with ctx.Err().
+func (m *Monitor) Collect(ctx context.Context) ([]Snapshot, error) {
+	return m.Run(ctx, nil)
+}
+
+// Run starts the monitoring process and returns collected snapshots.
+// onSnapshot, if not nil, is called for every poll, including failed ones.
+func (m *Monitor) Run(ctx context.Context, onSnapshot func(snapshot Snapshot, index int)) ([]Snapshot, error) {
+	offsets := sampleOffsets(m.duration*1000, m.intervalMs)
+	var snapshots []Snapshot
+	startTime, prevSnapshot := m.start()
+	index := 0
+
+	for k, offset := range offsets {
+		select {
+		case <-ctx.Done():
+			return snapshots, ctx.Err()
+		default:
+		}
+
+		tickStart := time.Now()
+		snapshot, err := m.takeSnapshot(ctx, startTime, prevSnapshot)
+		if err != nil {
+			// An interrupt aborts the request, that is not a failed poll
+			if ctx.Err() != nil {
+				return snapshots, ctx.Err()
+			}
+			m.attempted.Add(1)
+			m.failed.Add(1)
+			// The node rejected the request itself (e.g. unknown account),
+			// polling again won't help
+			if !IsRetryable(err) {
+				return snapshots, err
+			}
+			if onSnapshot != nil {
+				onSnapshot(Snapshot{Timestamp: time.Now(), ElapsedMs: time.Since(startTime).Milliseconds(), Failed: true}, index)
+			}
+		} else {
+			m.attempted.Add(1)
+			if !m.discard {
+				snapshots = append(snapshots, *snapshot)
+			}
+			if onSnapshot != nil {
+				onSnapshot(*snapshot, index)
+			}
+			prevSnapshot = snapshot
+		}
+		index++
+
+		if k < len(offsets)-1 {
+			if err := waitNext(ctx, tickStart, offsets[k+1]-offset); err != nil {
+				return snapshots, err
+			}
+		}
+	}
+
+	return snapshots, nil
+}
+
+// RunUntilFull monitors until resources are fully recovered, or have reached
+// the target set by SetRecoveryTarget
+func (m *Monitor) RunUntilFull(ctx context.Context, maxDuration int, onSnapshot func(snapshot Snapshot, index int)) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	startTime, prevSnapshot := m.start()
+	var firstSnapshot *Snapshot
+
+	for i := 0; i <= maxDuration; i++ {
+		select {
+		case <-ctx.Done():
+			return snapshots, ctx.Err()
+		default:
+		}
+
+		tickStart := time.Now()
+		snapshot, err := m.takeSnapshot(ctx, startTime, prevSnapshot)
+		if err != nil {
+			// An interrupt aborts the request, that is not a failed poll
+			if ctx.Err() != nil {
+				return snapshots, ctx.Err()
+			}
+			m.attempted.Add(1)
+			m.failed.Add(1)
+			// The node rejected the request itself (e.g. unknown account),
+			// polling again won't help
+			if !IsRetryable(err) {
+				return snapshots, err
+			}
+			if onSnapshot != nil {
+				onSnapshot(Snapshot{Timestamp: time.Now(), ElapsedMs: time.Since(startTime).Milliseconds(), Failed: true}, i)
+			}
+		} else {
+			m.attempted.Add(1)
+
+			// The first snapshot is the baseline of the recovery progress
+			if firstSnapshot == nil {
+				firstSnapshot = snapshot
+			}
+			progress := recoveryProgress(*firstSnapshot, *snapshot, m.target)
+			snapshot.Recovery = &progress
+
+			if !m.discard {
+				snapshots = append(snapshots, *snapshot)
+			}
+			if onSnapshot != nil {
+				onSnapshot(*snapshot, i)
+			}
+
+			// Check if recovered
+			if m.target.Reached(*snapshot) {
+				return snapshots, nil
+			}
+
+			prevSnapshot = snapshot
+		}
+
+		if i < maxDuration {
+			if err := waitNext(ctx, tickStart, m.intervalMs); err != nil {
+				return snapshots, err
+			}
+		}
+	}
+
+	return snapshots, nil
+}
+
+// recoveryProgress compares s with the baseline first. A resource that was
+// fully available at the baseline counts as 100% while it stays unused.
+// The ETA is until target is reached.
+func recoveryProgress(first, s Snapshot, target RecoveryTarget) RecoveryProgress {
+	elapsedSec := float64(s.ElapsedMs-first.ElapsedMs) / 1000.0
+	energyPercent, energyETA := resourceRecovery(first.EnergyUsed, s.EnergyUsed, target.usedGoal(s.EnergyLimit), elapsedSec)
+	bandwidthPercent, bandwidthETA := resourceRecovery(first.TotalBandwidthUsed(), s.TotalBandwidthUsed(), target.usedGoal(s.TotalBandwidthLimit()), elapsedSec)
+
+	// With both targeted the slower one decides
+	var eta time.Duration
+	switch target.Resource {
+	case RecoverEnergy:
+		eta = energyETA
+	case RecoverBandwidth:
+		eta = bandwidthETA
+	default:
+		eta = max(energyETA, bandwidthETA)
+		if energyETA < 0 || bandwidthETA < 0 {
+			eta = -1
+		}
+	}
+
+	return RecoveryProgress{
+		EnergyPercent:    energyPercent,
+		BandwidthPercent: bandwidthPercent,
+		ETA:              eta,
+	}
+}
+
+// resourceRecovery returns the recovered percentage of one resource and the
+// time until at most goal is used at the rate it regenerated since the
+// baseline, negative when that rate is not positive
+func resourceRecovery(baseUsed, used, goal int64, elapsedSec float64) (float64, time.Duration) {
+	if used == 0 {
+		return 100, 0
+	}
+
+	percent := 0.0
+	if baseUsed > 0 {
+		percent = math.Max(0, 100*float64(baseUsed-used)/float64(baseUsed))
+	}
+	if used <= goal {
+		return percent, 0
+	}
+
+	recovered := baseUsed - used
+	if recovered <= 0 || elapsedSec <= 0 {
+		return percent, -1
+	}
+	seconds := float64(used-goal) / (float64(recovered) / elapsedSec)
+	return percent, time.Duration(seconds * float64(time.Second))
+}
+
+// start returns the time elapsed times are measured from and the snapshot
+// deltas start from, which is the resumed session if there is one
+func (m *Monitor) start() (time.Time, *Snapshot) {
+	if m.resume == nil {
+		return time.Now(), nil
+	}
+	return m.resume.Timestamp.Add(-time.Duration(m.resume.ElapsedMs) * time.Millisecond), m.resume
+}
+
+// SampleCount returns the number of samples Run takes for a duration in
+// seconds: one every intervalMs from 0, plus a final one at the duration
+// when the interval doesn't divide it evenly
+func SampleCount(duration, intervalMs int) int {
+	return len(sampleOffsets(duration*1000, intervalMs))
+}
+
+// sampleOffsets returns the planned sample times in ms since the start. The
+// last one is snapped to durationMs, so the run covers exactly the duration
+// and the final interval is shorter when intervalMs doesn't divide it.
+func sampleOffsets(durationMs, intervalMs int) []int {
+	if intervalMs <= 0 || durationMs <= 0 {
+		return []int{0}
+	}
+
+	offsets := make([]int, 0, durationMs/intervalMs+2)
+	for offset := 0; offset <= durationMs; offset += intervalMs {
+		offsets = append(offsets, offset)
+	}
+	if offsets[len(offsets)-1] != durationMs {
+		offsets = append(offsets, durationMs)
+	}
+	return offsets
+}
+
+// waitNext sleeps until delayMs after tickStart, so the request latency
+// is absorbed by the sleep and samples keep a steady cadence. A request
+// slower than the delay is followed by the next sample right away.
+func waitNext(ctx context.Context, tickStart time.Time, delayMs int) error {
+	wait := time.Duration(delayMs)*time.Millisecond - time.Since(tickStart)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// IntervalStats returns the mean and standard deviation of the actual
+// spacing between consecutive snapshots, in milliseconds
+func IntervalStats(snapshots []Snapshot) (mean, stddev float64) {
+	var intervals []float64
+	for i := 1; i < len(snapshots); i++ {
+		if snapshots[i].ResumeGap {
+			continue // the pause between resumed sessions is not a sample interval
+		}
+		intervals = append(intervals, float64(snapshots[i].ElapsedMs-snapshots[i-1].ElapsedMs))
+	}
+	if len(intervals) == 0 {
+		return 0, 0
+	}
+
+	n := float64(len(intervals))
+	for _, v := range intervals {
+		mean += v
+	}
+	mean /= n
+
+	for _, v := range intervals {
+		d := v - mean
+		stddev += d * d
+	}
+	stddev = math.Sqrt(stddev / n)
+
+	return mean, stddev
+}
+
+// clockSkew returns how much further the wall clock than the monotonic clock
+// moved from prev to now, or 0 within maxClockSkew. Timestamps without a
+// monotonic reading, as loaded from a report, can't be checked.
+func clockSkew(prev, now time.Time) time.Duration {
+	if prev.Round(0) == prev {
+		return 0
+	}
+	skew := now.Round(0).Sub(prev.Round(0)) - now.Sub(prev)
+	if skew.Abs() <= maxClockSkew {
+		return 0
+	}
+	return skew
+}
+
+// ClockAnomalies returns the number of snapshots taken across a jump of the
+// system clock
+func ClockAnomalies(snapshots []Snapshot) int {
+	n := 0
+	for _, s := range snapshots {
+		if s.ClockAnomaly {
+			n++
+		}
+	}
+	return n
+}
+
+// TakeSnapshot fetches the current resources of address once. The snapshot
+// is the start of its own time line, so it has no elapsed time or deltas.
+func TakeSnapshot(ctx context.Context, c ResourceClient, address string) (Snapshot, error) {
+	resp, err := c.GetAccountResource(ctx, address)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	now := time.Now()
+	return *newSnapshot(resp, now, now), nil
+}
+
+func (m *Monitor) takeSnapshot(ctx context.Context, startTime time.Time, prev *Snapshot) (*Snapshot, error) {
+	resp, err := m.client.GetAccountResource(ctx, m.address)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	snapshot := newSnapshot(resp, now, startTime)
+	if prev != nil {
+		snapshot.ResumeGap = prev == m.resume
+		if !snapshot.ResumeGap {
+			snapshot.ClockSkewMs = clockSkew(prev.Timestamp, now).Milliseconds()
+			snapshot.ClockAnomaly = snapshot.ClockSkewMs != 0
+		}
+		snapshot.setDeltas(*prev)
+	}
+
+	return snapshot, nil
+}
+
+// newSnapshot builds the snapshot of a response received at now
+func newSnapshot(resp *APIResponse, now, startTime time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		Timestamp:    now,
+		ElapsedMs:    now.Sub(startTime).Milliseconds(),
+		EnergyLimit:  resp.EnergyLimit,
+		EnergyUsed:   resp.EnergyUsed,
+		NetLimit:     resp.NetLimit,
+		NetUsed:      resp.NetUsed,
+		FreeNetLimit: resp.FreeNetLimit,
+		FreeNetUsed:  resp.FreeNetUsed,
+
+		TotalEnergyLimit:  resp.TotalEnergyLimit,
+		TotalEnergyWeight: resp.TotalEnergyWeight,
+		TotalNetLimit:     resp.TotalNetLimit,
+		TotalNetWeight:    resp.TotalNetWeight,
+	}
+
+	snapshot.compute()
+
+	return snapshot
+}
+
+// compute sets the available amounts and Inactive from the limits and used amounts
+func (s *Snapshot) compute() {
+	s.EnergyAvailable = s.EnergyLimit - s.EnergyUsed
+	s.StakedBandwidthAvailable = s.NetLimit - s.NetUsed
+	s.FreeBandwidthAvailable = s.FreeNetLimit - s.FreeNetUsed
+	s.BandwidthAvailable = s.StakedBandwidthAvailable + s.FreeBandwidthAvailable
+	s.Inactive = inactive(*s)
+}
+
+// setDeltas sets the deltas of s against the previous snapshot
+func (s *Snapshot) setDeltas(prev Snapshot) {
+	s.DeltaEnergy = s.EnergyAvailable - prev.EnergyAvailable
+	s.DeltaBandwidth = s.BandwidthAvailable - prev.BandwidthAvailable
+	s.DeltaStakedBandwidth = s.StakedBandwidthAvailable - prev.StakedBandwidthAvailable
+	s.DeltaFreeBandwidth = s.FreeBandwidthAvailable - prev.FreeBandwidthAvailable
+}
+
+// DeriveSnapshots returns a copy of snapshots with every computed field
+// derived again from the limits and used amounts: the available amounts,
+// Inactive and the deltas. Snapshots without elapsed times, as written by
+// other tools, get them from their timestamps. The snapshots are sorted by
+// time first, so the deltas never run backwards. The ResumeGap and
+// ClockAnomaly marks are kept.
+func DeriveSnapshots(snapshots []Snapshot) []Snapshot {
+	derived := slices.Clone(snapshots)
+	elapsedMissing := len(derived) > 1 && !slices.ContainsFunc(derived, func(s Snapshot) bool { return s.ElapsedMs != 0 })
+	if elapsedMissing {
+		slices.SortStableFunc(derived, func(a, b Snapshot) int { return a.Timestamp.Compare(b.Timestamp) })
+	} else {
+		slices.SortStableFunc(derived, func(a, b Snapshot) int { return cmp.Compare(a.ElapsedMs, b.ElapsedMs) })
+	}
+	for i := range derived {
+		s := &derived[i]
+		s.compute()
+		if elapsedMissing {
+			s.ElapsedMs = s.Timestamp.Sub(derived[0].Timestamp).Milliseconds()
+		}
+		if i == 0 {
+			s.DeltaEnergy, s.DeltaBandwidth, s.DeltaStakedBandwidth, s.DeltaFreeBandwidth = 0, 0, 0, 0
+		} else {
+			s.setDeltas(derived[i-1])
+		}
+	}
+	return derived
+}
+
+// AnalyzeOptions tunes Analyze. The zero value gives the default analysis.
+type AnalyzeOptions struct {
+	// Prices are used to estimate the TRX burned for the observed consumption
+	Prices ResourcePrices
+	// IntervalMs is the requested sampling interval, used to warn about drift (0 = don't check)
+	IntervalMs int
+	// AttemptedSamples is the number of polls the snapshots came from, used
+	// for Analysis.SampleSuccessRate (0 = unknown, e.g. an older report)
+	AttemptedSamples int
+	// FilterOutliers rejects positive delta spikes, e.g. from a sample the node
+	// answered with stale data, instead of counting them as regeneration
+	FilterOutliers bool
+	// Window splits the session into windows of this length for
+	// Analysis.RateWindows (0 = no windows)
+	Window time.Duration
+}
+
+// maxIntervalDrift is the relative deviation of the actual mean sample
+// spacing from the requested interval above which Analyze warns
+const maxIntervalDrift = 0.2
+
+// maxClockSkew is the difference between the wall clock and the monotonic
+// clock over one interval above which a snapshot is a clock anomaly. Small
+// NTP slews stay well below it.
+const maxClockSkew = 2 * time.Second
+
+// minSampleSuccessRate is the share of successful polls below which Analyze
+// warns that the rates rest on too little data
+const minSampleSuccessRate = 0.8
+
+// Analyze computes statistics from collected snapshots.
+// Rates are based on the snapshot timestamps, not the requested duration.
+func Analyze(snapshots []Snapshot) Analysis {
+	return AnalyzeWithOptions(snapshots, AnalyzeOptions{})
+}
+
+// AnalyzeWithOptions computes statistics from collected snapshots with custom options
+func AnalyzeWithOptions(snapshots []Snapshot, opts AnalyzeOptions) Analysis {
+	if len(snapshots) == 0 {
+		return Analysis{}
+	}
+
+	// Outliers are flagged on a copy, the caller's snapshots stay untouched
+	var outliersRejected int
+	if opts.FilterOutliers {
+		snapshots = slices.Clone(snapshots)
+		outliersRejected = markOutliers(snapshots)
+	}
+
+	first := snapshots[0]
+	last := snapshots[len(snapshots)-1]
+
+	// Calculate actual duration from timestamps
+	actualDurationSec := float64(last.ElapsedMs-first.ElapsedMs) / 1000.0
+
+	// A stake or unstake moves the limit and with it the available amount,
+	// that jump is not regeneration or consumption. Neither is the delta
+	// across the pause of a resumed session.
+	limitChanges := detectLimitChanges(snapshots)
+	var boundaryMs int64
+
+	// Sum up regenerated and consumed separately
+	var energyRegenerated, energyConsumed int64
+	var bandwidthRegenerated, bandwidthConsumed int64
+	var stakedRegenerated, stakedConsumed, freeRegenerated, freeConsumed int64
+	var energyStats, bandwidthStats runningStats
+
+	for i, s := range snapshots {
+		energyStats.add(s.EnergyAvailable)
+		bandwidthStats.add(s.BandwidthAvailable)
+		if i == 0 {
+			continue // the first snapshot has no delta
+		}
+		if excludedDelta(snapshots[i-1], s) {
+			boundaryMs += s.ElapsedMs - snapshots[i-1].ElapsedMs
+			continue
+		}
+
+		if s.DeltaEnergy > 0 {
+			energyRegenerated += s.DeltaEnergy
+		} else if s.DeltaEnergy < 0 {
+			energyConsumed += -s.DeltaEnergy // store as positive
+		}
+
+		if s.DeltaBandwidth > 0 {
+			bandwidthRegenerated += s.DeltaBandwidth
+		} else if s.DeltaBandwidth < 0 {
+			bandwidthConsumed += -s.DeltaBandwidth // store as positive
+		}
+
+		if s.DeltaStakedBandwidth > 0 {
+			stakedRegenerated += s.DeltaStakedBandwidth
+		} else if s.DeltaStakedBandwidth < 0 {
+			stakedConsumed += -s.DeltaStakedBandwidth
+		}
+
+		if s.DeltaFreeBandwidth > 0 {
+			freeRegenerated += s.DeltaFreeBandwidth
+		} else if s.DeltaFreeBandwidth < 0 {
+			freeConsumed += -s.DeltaFreeBandwidth
+		}
+	}
+
+	analysis := Analysis{
+		ActualDurationSec: actualDurationSec,
+
+		EnergyStart:       first.EnergyAvailable,
+		EnergyEnd:         last.EnergyAvailable,
+		EnergyTotalDelta:  last.EnergyAvailable - first.EnergyAvailable,
+		EnergyRegenerated: energyRegenerated,
+		EnergyConsumed:    energyConsumed,
+
+		BandwidthStart:       first.BandwidthAvailable,
+		BandwidthEnd:         last.BandwidthAvailable,
+		BandwidthTotalDelta:  last.BandwidthAvailable - first.BandwidthAvailable,
+		BandwidthRegenerated: bandwidthRegenerated,
+		BandwidthConsumed:    bandwidthConsumed,
+
+		StakedBandwidthRegenerated: stakedRegenerated,
+		StakedBandwidthConsumed:    stakedConsumed,
+		FreeBandwidthRegenerated:   freeRegenerated,
+		FreeBandwidthConsumed:      freeConsumed,
+
+		EnergyAvailableStats:    energyStats.result(),
+		BandwidthAvailableStats: bandwidthStats.result(),
+
+		LimitChangeEvents: limitChanges,
+
+		OutlierFiltering: opts.FilterOutliers,
+		OutliersRejected: outliersRejected,
+	}
+
+	// Rates only cover the intervals whose deltas were counted
+	rateDurationSec := actualDurationSec - float64(boundaryMs)/1000.0
+
+	// Calculate separated rates
+	if rateDurationSec > 0 {
+		// Regeneration rates
+		analysis.EnergyRegenRatePerSec = float64(energyRegenerated) / rateDurationSec
+		analysis.EnergyRegenRatePerDay = analysis.EnergyRegenRatePerSec * 86400
+
+		analysis.BandwidthRegenRatePerSec = float64(bandwidthRegenerated) / rateDurationSec
+		analysis.BandwidthRegenRatePerDay = analysis.BandwidthRegenRatePerSec * 86400
+
+		// Consumption rates
+		analysis.EnergyConsumeRatePerSec = float64(energyConsumed) / rateDurationSec
+		analysis.EnergyConsumeRatePerDay = analysis.EnergyConsumeRatePerSec * 86400
+
+		analysis.BandwidthConsumeRatePerSec = float64(bandwidthConsumed) / rateDurationSec
+		analysis.BandwidthConsumeRatePerDay = analysis.BandwidthConsumeRatePerSec * 86400
+
+		// Net rates (regen - consume)
+		analysis.EnergyNetRatePerSec = analysis.EnergyRegenRatePerSec - analysis.EnergyConsumeRatePerSec
+		analysis.EnergyNetRatePerDay = analysis.EnergyNetRatePerSec * 86400
+
+		analysis.BandwidthNetRatePerSec = analysis.BandwidthRegenRatePerSec - analysis.BandwidthConsumeRatePerSec
+		analysis.BandwidthNetRatePerDay = analysis.BandwidthNetRatePerSec * 86400
+
+		// Per-source bandwidth regeneration
+		analysis.StakedBandwidthRegenRatePerDay = float64(stakedRegenerated) / rateDurationSec * 86400
+		analysis.FreeBandwidthRegenRatePerDay = float64(freeRegenerated) / rateDurationSec * 86400
+	}
+
+	// Theoretical rates
+	analysis.TheoreticalEnergyRatePerDay = float64(first.EnergyLimit)
+	analysis.TheoreticalBandwidthRatePerDay = float64(first.TotalBandwidthLimit())
+
+	// Check theoretical match (10% tolerance) - compare REGEN rate, not net
+	if analysis.TheoreticalEnergyRatePerDay > 0 && analysis.EnergyRegenRatePerDay > 0 {
+		ratio := analysis.EnergyRegenRatePerDay / analysis.TheoreticalEnergyRatePerDay
+		analysis.EnergyRateMatchesTheory = math.Abs(ratio-1.0) < 0.1
+	}
+
+	if analysis.TheoreticalBandwidthRatePerDay > 0 && analysis.BandwidthRegenRatePerDay > 0 {
+		ratio := analysis.BandwidthRegenRatePerDay / analysis.TheoreticalBandwidthRatePerDay
+		analysis.BandwidthRateMatchesTheory = math.Abs(ratio-1.0) < 0.1
+	}
+
+	// Network share, only the staked bandwidth comes from the network total
+	if last.TotalEnergyLimit > 0 {
+		analysis.EnergyShareRatio = float64(last.EnergyLimit) / float64(last.TotalEnergyLimit)
+	}
+	if last.TotalEnergyWeight > 0 {
+		analysis.EnergyPerStakedTRX = float64(last.TotalEnergyLimit) / float64(last.TotalEnergyWeight)
+	}
+	if last.TotalNetLimit > 0 {
+		analysis.BandwidthShareRatio = float64(last.NetLimit) / float64(last.TotalNetLimit)
+	}
+	if last.TotalNetWeight > 0 {
+		analysis.BandwidthPerStakedTRX = float64(last.TotalNetLimit) / float64(last.TotalNetWeight)
+	}
+
+	// Transaction estimates based on REGEN rate
+	if analysis.EnergyRegenRatePerDay > 0 {
+		analysis.TxPerDay65k = analysis.EnergyRegenRatePerDay / 65000
+		analysis.TxPerDay131k = analysis.EnergyRegenRatePerDay / 131000
+	}
+
+	// Extended analysis
+	analysis.TickAnalysis = analyzeBlockTicks(snapshots)
+	analysis.RegressionAnalysis = analyzeRegression(snapshots)
+	analysis.UsedBasedAnalysis = analyzeUsedBased(snapshots, analysis.EnergyRegenRatePerSec)
+	analysis.FormulaValidation = validateFormulas(analysis, first)
+	analysis.PracticalEstimates = calculatePracticalEstimates(first, analysis, opts.Prices)
+	if opts.Window > 0 {
+		analysis.RateWindows = rateWindows(snapshots, opts.Window)
+	}
+
+	// Failed polls leave gaps the rates are interpolated across
+	if opts.AttemptedSamples > 0 {
+		analysis.SampleSuccessRate = min(float64(len(snapshots))/float64(opts.AttemptedSamples), 1)
+		if analysis.SampleSuccessRate < minSampleSuccessRate {
+			analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+				"only %d of %d polls returned data (%.0f%%), the rates are based on too few samples to be trusted",
+				len(snapshots), opts.AttemptedSamples, analysis.SampleSuccessRate*100))
+		}
+	}
+
+	// Rates use actual timestamps, but a large drift means fewer samples
+	// than expected and coarser tick detection. A short final interval is
+	// the sample snapped to the duration boundary, not drift.
+	sampled := snapshots
+	if n := len(sampled); n > 2 && sampled[n-1].ElapsedMs-sampled[n-2].ElapsedMs < int64(opts.IntervalMs) {
+		sampled = sampled[:n-1]
+	}
+	if meanInterval, _ := IntervalStats(sampled); opts.IntervalMs > 0 && meanInterval > 0 {
+		drift := (meanInterval - float64(opts.IntervalMs)) / float64(opts.IntervalMs)
+		if math.Abs(drift) > maxIntervalDrift {
+			analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+				"actual sample interval averaged %.0fms vs %dms requested (%+.0f%% drift)",
+				meanInterval, opts.IntervalMs, drift*100))
+		}
+	}
+
+	if anomalies := ClockAnomalies(snapshots); anomalies > 0 {
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+			"the system clock jumped during %d interval(s) (NTP step or sleep/wake), their deltas are excluded from rates",
+			anomalies))
+	}
+
+	if outliersRejected > 0 {
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+			"%d delta spike(s) rejected as outliers and excluded from rates", outliersRejected))
+	}
+
+	// Checked on the limits, so reports saved before the flag existed are detected too
+	if inactive(last) {
+		analysis.AccountInactive = true
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf(
+			"account appears inactive (all resource limits are zero); once activated by a first transfer "+
+				"it only gets the %d free bandwidth per day until TRX is staked", FreeBandwidthPerDay))
+	}
+
+	return analysis
+}
+
+// inactive reports whether s looks like an account that is not activated:
+// the node reports no energy, staked bandwidth or free bandwidth limit at all
+func inactive(s Snapshot) bool {
+	return s.EnergyLimit == 0 && s.NetLimit == 0 && s.FreeNetLimit == 0
+}
+
+// excludedDelta reports whether the delta of s against prev is an artifact
+// (a limit change, the pause before a resumed session, a clock jump or a
+// rejected outlier) rather than regeneration or consumption
+func excludedDelta(prev, s Snapshot) bool {
+	return s.outlier || ExcludedDelta(prev, s)
+}
+
+// ExcludedDelta reports whether Analyze leaves the delta of s against the
+// previous snapshot prev out of the totals and rates: a limit change, the
+// pause before a resumed session or a clock jump. Outlier filtering is not
+// covered, it needs the whole run.
+func ExcludedDelta(prev, s Snapshot) bool {
+	return s.ResumeGap || s.ClockAnomaly || limitChanged(prev, s)
+}
+
+// limitChanged reports whether the energy or bandwidth limit differs between two consecutive snapshots
+func limitChanged(prev, s Snapshot) bool {
+	return prev.EnergyLimit != s.EnergyLimit || prev.TotalBandwidthLimit() != s.TotalBandwidthLimit()
+}
+
+// detectLimitChanges lists every change of the energy and bandwidth limits
+func detectLimitChanges(snapshots []Snapshot) []LimitChangeEvent {
+	var events []LimitChangeEvent
+	for i := 1; i < len(snapshots); i++ {
+		prev, s := snapshots[i-1], snapshots[i]
+		if prev.EnergyLimit != s.EnergyLimit {
+			events = append(events, LimitChangeEvent{
+				Timestamp: s.Timestamp,
+				ElapsedMs: s.ElapsedMs,
+				Resource:  ResourceEnergy,
+				OldLimit:  prev.EnergyLimit,
+				NewLimit:  s.EnergyLimit,
+			})
+		}
+		if prev.TotalBandwidthLimit() != s.TotalBandwidthLimit() {
+			events = append(events, LimitChangeEvent{
+				Timestamp: s.Timestamp,
+				ElapsedMs: s.ElapsedMs,
+				Resource:  ResourceBandwidth,
+				OldLimit:  prev.TotalBandwidthLimit(),
+				NewLimit:  s.TotalBandwidthLimit(),
+			})
+		}
+	}
+	return events
+}
+
+// analyzeBlockTicks detects recovery ticks and consumption events
+func analyzeBlockTicks(snapshots []Snapshot) TickAnalysis {
+	tick := TickAnalysis{
+		TickTimestampsMs:    make([]int64, 0),
+		TickEnergyDeltas:    make([]int64, 0),
+		TickBandwidthDeltas: make([]int64, 0),
+	}
+
+	if len(snapshots) < 2 {
+		return tick
+	}
+
+	var totalRegenEnergy, totalRegenBandwidth int64
+	var recoveryTimestamps []int64
+	var recoveryDeltas []float64
+
+	for i := 1; i < len(snapshots); i++ {
+		s := snapshots[i]
+
+		// Track all deltas for raw data
+		tick.TickTimestampsMs = append(tick.TickTimestampsMs, s.ElapsedMs)
+		tick.TickEnergyDeltas = append(tick.TickEnergyDeltas, s.DeltaEnergy)
+		tick.TickBandwidthDeltas = append(tick.TickBandwidthDeltas, s.DeltaBandwidth)
+
+		// A limit change or resume gap is neither a tick nor a consumption
+		if excludedDelta(snapshots[i-1], s) {
+			continue
+		}
+
+		// Count recovery ticks (positive deltas)
+		if s.DeltaEnergy > 0 {
+			tick.RecoveryTicks++
+			totalRegenEnergy += s.DeltaEnergy
+			totalRegenBandwidth += s.DeltaBandwidth
+			recoveryTimestamps = append(recoveryTimestamps, s.ElapsedMs)
+			recoveryDeltas = append(recoveryDeltas, float64(s.DeltaEnergy))
+		}
+
+		// Count consumption events (negative deltas)
+		if s.DeltaEnergy < 0 {
+			tick.ConsumptionEvents++
+			tick.TotalEnergyConsumed += -s.DeltaEnergy
+		}
+		if s.DeltaBandwidth < 0 {
+			tick.TotalBandwidthConsumed += -s.DeltaBandwidth
+		}
+	}
+
+	// Calculate recovery tick stats
+	if tick.RecoveryTicks > 0 {
+		tick.EnergyPerTick = float64(totalRegenEnergy) / float64(tick.RecoveryTicks)
+		tick.BandwidthPerTick = float64(totalRegenBandwidth) / float64(tick.RecoveryTicks)
+
+		// Calculate average interval between recovery ticks
+		if len(recoveryTimestamps) > 1 {
+			firstTickMs := recoveryTimestamps[0]
+			lastTickMs := recoveryTimestamps[len(recoveryTimestamps)-1]
+			totalIntervalMs := lastTickMs - firstTickMs
+			avgIntervalMs := float64(totalIntervalMs) / float64(len(recoveryTimestamps)-1)
+			tick.AvgRecoveryInterval = avgIntervalMs / 1000.0
+
+			if avgIntervalMs > 0 {
+				tick.RecoveryTicksPerHr = 3600000.0 / avgIntervalMs
+				tick.RecoveryTicksPerDay = 86400000.0 / avgIntervalMs
+			}
+
+			intervals := make([]float64, 0, len(recoveryTimestamps)-1)
+			for i := 1; i < len(recoveryTimestamps); i++ {
+				intervals = append(intervals, float64(recoveryTimestamps[i]-recoveryTimestamps[i-1])/1000.0)
+			}
+			tick.MedianRecoveryInterval = percentile(intervals, 50)
+			tick.P90RecoveryInterval = percentile(intervals, 90)
+			tick.P95RecoveryInterval = percentile(intervals, 95)
+			tick.RecoveryIntervalStddev, tick.RecoveryIntervalCV = spread(intervals)
+		}
+
+		tick.MedianEnergyPerTick = percentile(recoveryDeltas, 50)
+		tick.P90EnergyPerTick = percentile(recoveryDeltas, 90)
+		tick.P95EnergyPerTick = percentile(recoveryDeltas, 95)
+		tick.EnergyPerTickStddev, tick.EnergyPerTickCV = spread(recoveryDeltas)
+	}
+
+	// Calculate consumption stats
+	if tick.ConsumptionEvents > 0 {
+		tick.AvgEnergyPerConsume = float64(tick.TotalEnergyConsumed) / float64(tick.ConsumptionEvents)
+		tick.AvgBandwidthPerConsume = float64(tick.TotalBandwidthConsumed) / float64(tick.ConsumptionEvents)
+	}
+
+	return tick
+}
+
+// analyzeUsedBased computes analysis based on energy_used
+func analyzeUsedBased(snapshots []Snapshot, measuredRate float64) UsedBasedAnalysis {
+	if len(snapshots) == 0 {
+		return UsedBasedAnalysis{}
+	}
+
+	first := snapshots[0]
+
+	analysis := UsedBasedAnalysis{
+		EnergyUsedAtStart:    first.EnergyUsed,
+		BandwidthUsedAtStart: first.TotalBandwidthUsed(),
+		MeasuredRecoveryRate: measuredRate,
+	}
+
+	if first.EnergyLimit > 0 {
+		analysis.EnergyUsedRatio = float64(first.EnergyUsed) / float64(first.EnergyLimit)
+	}
+
+	if first.TotalBandwidthLimit() > 0 {
+		analysis.BandwidthUsedRatio = float64(first.TotalBandwidthUsed()) / float64(first.TotalBandwidthLimit())
+	}
+
+	// Calculate used-based recovery rate (energy_used / 24h in seconds)
+	if first.EnergyUsed > 0 {
+		// Full recovery takes 24h, so rate = energy_used / 86400
+		analysis.UsedBasedRecoveryRate = float64(first.EnergyUsed) / 86400.0
+
+		// Estimated time to full recovery
+		if measuredRate > 0 {
+			analysis.EstimatedFullRecoverySeconds = float64(first.EnergyUsed) / measuredRate
+			analysis.EstimatedFullRecoveryHours = analysis.EstimatedFullRecoverySeconds / 3600.0
+		}
+
+		// Check if measured rate matches used-based model (within 15% tolerance)
+		if analysis.UsedBasedRecoveryRate > 0 && measuredRate > 0 {
+			ratio := measuredRate / analysis.UsedBasedRecoveryRate
+			analysis.EnergyRecoveryMatchesUsedModel = math.Abs(ratio-1.0) < 0.15
+		}
+	}
+
+	return analysis
+}
+
+// validateFormulas compares theoretical and measured models
+func validateFormulas(analysis Analysis, first Snapshot) FormulaValidation {
+	validation := FormulaValidation{
+		TheoreticalModel: "E_limit / 86400",
+		MeasuredModel:    "E_used / T_recovery",
+	}
+
+	// Calculate theoretical rate from limit
+	theoreticalFromLimit := float64(first.EnergyLimit) / 86400.0
+
+	// Calculate theoretical rate from used (assuming 24h full recovery)
+	theoreticalFromUsed := float64(first.EnergyUsed) / 86400.0
+
+	// Use REGEN rate for comparison
+	measured := analysis.EnergyRegenRatePerSec
+
+	if measured > 0 && theoreticalFromLimit > 0 && theoreticalFromUsed > 0 {
+		diffLimit := math.Abs(measured-theoreticalFromLimit) / theoreticalFromLimit
+		diffUsed := math.Abs(measured-theoreticalFromUsed) / theoreticalFromUsed
+
+		if diffUsed < diffLimit {
+			validation.BestFit = "used_based"
+			validation.Confidence = 1.0 - diffUsed
+		} else {
+			validation.BestFit = "limit_based"
+			validation.Confidence = 1.0 - diffLimit
+		}
+
+		if validation.Confidence < 0 {
+			validation.Confidence = 0
+		}
+	}
+
+	return validation
+}
+
+// calculatePracticalEstimates computes transaction capacity
+func calculatePracticalEstimates(first Snapshot, analysis Analysis, prices ResourcePrices) PracticalEstimates {
+	est := PracticalEstimates{
+		EnergyNeeded800Tx65k:  800 * 65000,
+		EnergyNeeded800Tx131k: 800 * 131000,
+	}
+
+	// Immediate capacity from available energy
+	if first.EnergyAvailable > 0 {
+		est.ImmediateCapacity65k = first.EnergyAvailable / 65000
+		est.ImmediateCapacity131k = first.EnergyAvailable / 131000
+	}
+
+	// Bandwidth capacity for plain transfers. Staked bandwidth uses the
+	// measured regen rate like energy does. The free allowance regenerates
+	// too slowly to measure in a short session, so its daily limit is used:
+	// it fully recovers within 24h.
+	est.TxPerDayTransferStaked = analysis.StakedBandwidthRegenRatePerDay / TransferBandwidthCost
+	est.TxPerDayTransferFree = float64(first.FreeNetLimit / TransferBandwidthCost)
+	est.TxPerDayTransfer = est.TxPerDayTransferStaked + est.TxPerDayTransferFree
+
+	// Sustained capacity (based on REGEN rate only)
+	if analysis.EnergyRegenRatePerDay > 0 {
+		est.TxPerDay65kSustained = analysis.EnergyRegenRatePerDay / 65000
+		est.TxPerDay131kSustained = analysis.EnergyRegenRatePerDay / 131000
+	}
+
+	// With buffer capacity (immediate + recovered)
+	recoveredPerDay := analysis.EnergyRegenRatePerDay
+	if recoveredPerDay > 0 {
+		totalEnergy := float64(first.EnergyAvailable) + recoveredPerDay
+		est.TxPerDay65kWithBuffer = totalEnergy / 65000
+		est.TxPerDay131kWithBuffer = totalEnergy / 131000
+	}
+
+	// TRX that would have been burned if the observed consumption had no resources to draw from
+	est.TrxBurnedEstimate = prices.BurnTRX(analysis.EnergyConsumed, analysis.BandwidthConsumed)
+	est.TrxBurnedPerDayEstimate = prices.BurnTRX(int64(analysis.EnergyConsumeRatePerDay), int64(analysis.BandwidthConsumeRatePerDay))
+
+	return est
+}
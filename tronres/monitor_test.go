@@ -0,0 +1,148 @@
+package tronres
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// stubClient answers the polls with responses in order, repeating the last
+// one, and fails the polls listed in fail with a retryable error
+type stubClient struct {
+	mu        sync.Mutex
+	calls     int
+	responses []APIResponse
+	fail      map[int]bool
+}
+
+func (c *stubClient) GetAccountResource(ctx context.Context, address string) (*APIResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	call := c.calls
+	c.calls++
+	if c.fail[call] {
+		return nil, errors.New("connection reset")
+	}
+	resp := c.responses[min(call, len(c.responses)-1)]
+	return &resp, nil
+}
+
+// energyResponses returns responses of an account with a 100k energy limit
+// and the given energy used per poll
+func energyResponses(used ...int64) []APIResponse {
+	responses := make([]APIResponse, len(used))
+	for i, u := range used {
+		responses[i] = APIResponse{EnergyLimit: 100000, EnergyUsed: u, NetLimit: 5000, FreeNetLimit: 600}
+	}
+	return responses
+}
+
+func TestSampleOffsets(t *testing.T) {
+	tests := []struct {
+		name       string
+		duration   int // seconds
+		intervalMs int
+		want       []int
+	}{
+		{"even split", 20, 2000, []int{0, 2000, 4000, 6000, 8000, 10000, 12000, 14000, 16000, 18000, 20000}},
+		{"uneven split snaps to the duration", 20, 3000, []int{0, 3000, 6000, 9000, 12000, 15000, 18000, 20000}},
+		{"interval longer than the duration", 20, 30000, []int{0, 20000}},
+		{"zero duration", 0, 1000, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sampleOffsets(tt.duration*1000, tt.intervalMs)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sampleOffsets(%d, %d) = %v, want %v", tt.duration*1000, tt.intervalMs, got, tt.want)
+			}
+			if n := SampleCount(tt.duration, tt.intervalMs); n != len(tt.want) {
+				t.Errorf("SampleCount(%d, %d) = %d, want %d", tt.duration, tt.intervalMs, n, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestCollectRates(t *testing.T) {
+	// 6 polls 200ms apart: four ticks of 1,000 regenerated, one spend of 12,000
+	client := &stubClient{responses: energyResponses(10000, 9000, 8000, 20000, 19000, 18000)}
+	m := NewMonitorWithInterval(client, "TTest", 1, 200)
+
+	snapshots, err := m.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if len(snapshots) != 6 {
+		t.Fatalf("got %d snapshots, want 6", len(snapshots))
+	}
+
+	a := Analyze(snapshots)
+	if a.EnergyRegenerated != 4000 || a.EnergyConsumed != 12000 {
+		t.Errorf("regenerated %d, consumed %d, want 4000 and 12000", a.EnergyRegenerated, a.EnergyConsumed)
+	}
+	if a.BandwidthRegenerated != 0 || a.BandwidthConsumed != 0 {
+		t.Errorf("bandwidth regenerated %d, consumed %d, want none", a.BandwidthRegenerated, a.BandwidthConsumed)
+	}
+	if a.ActualDurationSec < 0.9 || a.ActualDurationSec > 1.5 {
+		t.Fatalf("actual duration %.3fs, want about 1s", a.ActualDurationSec)
+	}
+	if want := 4000 / a.ActualDurationSec; math.Abs(a.EnergyRegenRatePerSec-want) > 1e-9 {
+		t.Errorf("regen rate %.3f/s, want %.3f/s", a.EnergyRegenRatePerSec, want)
+	}
+	if want := 12000 / a.ActualDurationSec; math.Abs(a.EnergyConsumeRatePerSec-want) > 1e-9 {
+		t.Errorf("consume rate %.3f/s, want %.3f/s", a.EnergyConsumeRatePerSec, want)
+	}
+}
+
+func TestCollectSkipsFailedPolls(t *testing.T) {
+	client := &stubClient{
+		responses: energyResponses(10000, 9000, 8000, 7000, 6000, 5000),
+		fail:      map[int]bool{2: true},
+	}
+	m := NewMonitorWithInterval(client, "TTest", 1, 200)
+
+	snapshots, err := m.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if attempted, failed := m.PollCounts(); attempted != 6 || failed != 1 {
+		t.Errorf("PollCounts() = %d, %d, want 6, 1", attempted, failed)
+	}
+
+	// The delta over the failed poll spans two ticks, no regeneration is lost
+	a := AnalyzeWithOptions(snapshots, AnalyzeOptions{AttemptedSamples: 6})
+	if len(snapshots) != 5 || a.EnergyRegenerated != 5000 {
+		t.Errorf("%d snapshots regenerated %d, want 5 and 5000", len(snapshots), a.EnergyRegenerated)
+	}
+	if want := 5.0 / 6; math.Abs(a.SampleSuccessRate-want) > 1e-9 {
+		t.Errorf("sample success rate %.3f, want %.3f", a.SampleSuccessRate, want)
+	}
+}
+
+func TestRunUntilFull(t *testing.T) {
+	client := &stubClient{responses: energyResponses(3000, 2000, 1000, 0)}
+	m := NewMonitorWithInterval(client, "TTest", 0, 100)
+
+	snapshots, err := m.RunUntilFull(context.Background(), 10, nil)
+	if err != nil {
+		t.Fatalf("RunUntilFull: %v", err)
+	}
+	if len(snapshots) != 4 {
+		t.Fatalf("got %d snapshots, want 4 (stop at full recovery)", len(snapshots))
+	}
+	if p := snapshots[3].Recovery; p == nil || p.EnergyPercent != 100 {
+		t.Errorf("final recovery %+v, want 100%% energy", p)
+	}
+
+	a := Analyze(snapshots)
+	if a.EnergyRegenerated != 3000 || a.EnergyConsumed != 0 {
+		t.Errorf("regenerated %d, consumed %d, want 3000 and 0", a.EnergyRegenerated, a.EnergyConsumed)
+	}
+	if want := 3000 / a.ActualDurationSec; math.Abs(a.EnergyRegenRatePerSec-want) > 1e-9 {
+		t.Errorf("regen rate %.3f/s, want %.3f/s", a.EnergyRegenRatePerSec, want)
+	}
+}
@@ -0,0 +1,78 @@
+package tronres
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Known public networks and their TronGrid endpoints
+var networkURLs = map[string]string{
+	"mainnet": "https://api.trongrid.io",
+	"nile":    "https://nile.trongrid.io",
+	"shasta":  "https://api.shasta.trongrid.io",
+}
+
+// NetworkURL returns the node URL for a named network (mainnet, nile, shasta)
+func NetworkURL(network string) (string, error) {
+	url, ok := networkURLs[strings.ToLower(network)]
+	if !ok {
+		return "", fmt.Errorf("unknown network %q (expected one of: %s)", network, strings.Join(Networks(), ", "))
+	}
+	return url, nil
+}
+
+// Networks returns the names of known networks
+func Networks() []string {
+	names := make([]string, 0, len(networkURLs))
+	for name := range networkURLs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeNodeURL validates a node URL and returns it without the API path,
+// so the client can append /wallet/... to it. The scheme must be http or
+// https. A path ending in the HTTP API, like https://api.trongrid.io/wallet
+// or .../wallet/getaccountresource, is cut off, and so is a trailing slash;
+// any other path is kept as the prefix of a reverse proxy.
+func NormalizeNodeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("node URL is empty")
+	}
+	if !strings.Contains(raw, "://") {
+		return "", fmt.Errorf("invalid node URL %q: missing scheme, did you mean https://%s?", raw, raw)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid node URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid node URL %q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid node URL %q: missing host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid node URL %q: query and fragment are not supported", raw)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "wallet" || segment == "walletsolidity" {
+			segments = segments[:i]
+			break
+		}
+	}
+	u.Path = strings.Join(segments, "/")
+	if u.Path != "" {
+		u.Path = "/" + u.Path
+	}
+	u.RawPath = ""
+
+	return u.String(), nil
+}
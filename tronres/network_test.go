@@ -0,0 +1,40 @@
+package tronres
+
+import "testing"
+
+func TestNormalizeNodeURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "api.trongrid.io", wantErr: true},
+		{raw: "ftp://x", wantErr: true},
+		{raw: "https://x/wallet", want: "https://x"},
+		{raw: "https://x/walletsolidity/", want: "https://x"},
+		{raw: "https://x/wallet/getaccountresource", want: "https://x"},
+		{raw: "https://x?key=1", wantErr: true},
+		{raw: "https://x#top", wantErr: true},
+		{raw: "https://api.trongrid.io/", want: "https://api.trongrid.io"},
+		{raw: "http://127.0.0.1:8090", want: "http://127.0.0.1:8090"},
+		{raw: "https://proxy.example/tron/wallet", want: "https://proxy.example/tron"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := NormalizeNodeURL(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeNodeURL(%q) = %q, want an error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeNodeURL(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeNodeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package tronres
+
+import "slices"
+
+const (
+	// outlierMADs is how far above the median, in scaled median absolute
+	// deviations, a positive delta may lie before it is rejected
+	outlierMADs = 3
+	// madScale makes the MAD comparable to a standard deviation for normal data
+	madScale = 1.4826
+
+	// regenWindowMs is the time TRON takes to fully recover a used resource
+	regenWindowMs = 86_400_000
+	// blockIntervalMs is one block; a sample may see one tick more than its interval covers
+	blockIntervalMs = 3000
+	// regenCapSlack is the headroom over the theoretical maximum regeneration
+	regenCapSlack = 2
+)
+
+// markOutliers flags snapshots whose positive energy or bandwidth delta is
+// a spike: more than the limit could regenerate in the interval, or more
+// than outlierMADs scaled MADs above the median positive delta. Flagged
+// snapshots are excluded like limit changes. It returns how many were flagged.
+func markOutliers(snapshots []Snapshot) int {
+	energyMedian, energyMAD := positiveDeltaSpread(snapshots, func(s Snapshot) int64 { return s.DeltaEnergy })
+	bwMedian, bwMAD := positiveDeltaSpread(snapshots, func(s Snapshot) int64 { return s.DeltaBandwidth })
+
+	rejected := 0
+	for i := 1; i < len(snapshots); i++ {
+		prev, s := snapshots[i-1], &snapshots[i]
+		if excludedDelta(prev, *s) {
+			continue
+		}
+
+		dtMs := s.ElapsedMs - prev.ElapsedMs
+		if isSpike(s.DeltaEnergy, s.EnergyLimit, dtMs, energyMedian, energyMAD) ||
+			isSpike(s.DeltaBandwidth, s.TotalBandwidthLimit(), dtMs, bwMedian, bwMAD) {
+			s.outlier = true
+			rejected++
+		}
+	}
+
+	return rejected
+}
+
+func isSpike(delta, limit, dtMs int64, median, mad float64) bool {
+	if delta <= 0 {
+		return false // consumption is real spending, only regeneration is capped
+	}
+
+	maxRegen := float64(limit) * float64(dtMs+blockIntervalMs) / regenWindowMs * regenCapSlack
+	if float64(delta) > maxRegen {
+		return true
+	}
+
+	// With identical ticks the MAD is 0 and every other value would be an outlier
+	return mad > 0 && float64(delta) > median+outlierMADs*madScale*mad
+}
+
+// positiveDeltaSpread returns the median and median absolute deviation of
+// the positive deltas picked by delta
+func positiveDeltaSpread(snapshots []Snapshot, delta func(Snapshot) int64) (median, mad float64) {
+	var values []float64
+	for i := 1; i < len(snapshots); i++ {
+		if d := delta(snapshots[i]); d > 0 && !excludedDelta(snapshots[i-1], snapshots[i]) {
+			values = append(values, float64(d))
+		}
+	}
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	median = percentile(values, 50)
+	deviations := slices.Clone(values)
+	for i, v := range deviations {
+		if v < median {
+			deviations[i] = median - v
+		} else {
+			deviations[i] = v - median
+		}
+	}
+	return median, percentile(deviations, 50)
+}
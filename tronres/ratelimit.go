@@ -0,0 +1,78 @@
+package tronres
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by all requests of a Client. It
+// holds up to one second worth of requests, so the polls of several
+// addresses can still go out together.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	delayed int
+	waited  time.Duration
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := math.Max(1, math.Floor(rps))
+	return &rateLimiter{
+		rate:   rps,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token, sleeping until it is due. A caller that finds the
+// bucket empty reserves the next token, so concurrent callers are spaced
+// out in arrival order. Returns ctx.Err() when ctx is cancelled meanwhile,
+// the reserved token is then given back and the wait is not counted.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill()
+	l.tokens--
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		l.delayed++
+		l.waited += delay
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.refill()
+		l.tokens = math.Min(l.burst, l.tokens+1)
+		l.delayed--
+		l.waited -= delay
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens due since the last call, l.mu must be held
+func (l *rateLimiter) refill() {
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// stats returns how many requests were delayed and for how long in total
+func (l *rateLimiter) stats() (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.delayed, l.waited
+}
@@ -0,0 +1,43 @@
+package tronres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefundsCancelledWait(t *testing.T) {
+	l := newRateLimiter(10)
+	for range 10 {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait within the burst: %v", err)
+		}
+	}
+
+	// The bucket is empty, this waiter reserves the next token and gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled wait returned %v, want context.Canceled", err)
+	}
+	if delayed, waited := l.stats(); delayed != 0 || waited != 0 {
+		t.Errorf("stats() = %d, %v after a cancelled wait, want 0, 0", delayed, waited)
+	}
+
+	// 150ms later one token is due again, it goes to the next caller right away
+	l.mu.Lock()
+	l.last = l.last.Add(-150 * time.Millisecond)
+	l.mu.Unlock()
+
+	start := time.Now()
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("acquire after the refund took %v, want no wait", elapsed)
+	}
+	if delayed, _ := l.stats(); delayed != 0 {
+		t.Errorf("%d requests delayed, want none", delayed)
+	}
+}
@@ -0,0 +1,83 @@
+package tronres
+
+import "math"
+
+// analyzeRegression fits energy_available against elapsed time with least squares.
+//
+// Consumption events break the series into recovery segments (runs of samples
+// with no negative delta). Each segment gets its own intercept while the slope
+// is shared, so consumption drops don't distort the fitted regeneration rate.
+func analyzeRegression(snapshots []Snapshot) RegressionAnalysis {
+	var result RegressionAnalysis
+
+	segments := recoverySegments(snapshots)
+
+	// Pooled within-segment sums (values centered on each segment's mean)
+	var sxx, sxy, syy float64
+	for _, seg := range segments {
+		if len(seg) < 2 {
+			continue
+		}
+
+		var meanX, meanY float64
+		for _, s := range seg {
+			meanX += float64(s.ElapsedMs) / 1000.0
+			meanY += float64(s.EnergyAvailable)
+		}
+		meanX /= float64(len(seg))
+		meanY /= float64(len(seg))
+
+		for _, s := range seg {
+			dx := float64(s.ElapsedMs)/1000.0 - meanX
+			dy := float64(s.EnergyAvailable) - meanY
+			sxx += dx * dx
+			sxy += dx * dy
+			syy += dy * dy
+		}
+
+		result.Segments++
+		result.Samples += len(seg)
+	}
+
+	if sxx == 0 {
+		return RegressionAnalysis{}
+	}
+
+	result.SlopePerSec = sxy / sxx
+	result.SlopePerDay = result.SlopePerSec * 86400
+
+	sse := syy - result.SlopePerSec*sxy
+	if sse < 0 {
+		sse = 0 // rounding
+	}
+	if syy > 0 {
+		result.RSquared = 1 - sse/syy
+	} else {
+		result.RSquared = 1 // perfectly flat segments are fitted exactly
+	}
+
+	// One slope plus one intercept per segment
+	dof := result.Samples - result.Segments - 1
+	if dof > 0 {
+		result.StdError = math.Sqrt(sse/float64(dof)) / math.Sqrt(sxx)
+	}
+
+	return result
+}
+
+// recoverySegments splits snapshots at consumption events (negative energy deltas)
+// and at limit changes or resume gaps
+func recoverySegments(snapshots []Snapshot) [][]Snapshot {
+	var segments [][]Snapshot
+	var current []Snapshot
+
+	for i, s := range snapshots {
+		if i > 0 && (s.DeltaEnergy < 0 || excludedDelta(snapshots[i-1], s)) {
+			segments = append(segments, current)
+			current = nil
+		}
+		current = append(current, s)
+	}
+
+	return append(segments, current)
+}
@@ -0,0 +1,226 @@
+package tronres
+
+import "math"
+
+// Binding constraints reported by Simulate
+const (
+	ConstraintEnergy    = "energy"
+	ConstraintBandwidth = "bandwidth"
+)
+
+// SimulateOptions describes the workload to simulate
+type SimulateOptions struct {
+	// TxCost is the energy cost per transaction (0 for energy-free transactions)
+	TxCost int64
+	// TxMix, if not empty, replaces TxCost with a weighted mix of transaction
+	// types. The energy cost is their weighted average (see BlendedTxCost).
+	TxMix []TxCostShare
+	// BandwidthCost is the bandwidth cost per transaction (0 to skip bandwidth simulation)
+	BandwidthCost int64
+	// TargetTx is the desired number of transactions per day
+	TargetTx int
+	// Prices are used to estimate the TRX burned for the shortfall
+	Prices ResourcePrices
+	// DelegatedInEnergy is the part of the energy limit delegated in by other
+	// accounts (ResourceBreakdown.EnergyDelegatedIn). When set, the capacity
+	// left if the delegation were reclaimed is reported as well.
+	DelegatedInEnergy int64
+}
+
+// Simulate calculates transaction simulation
+func Simulate(snapshot Snapshot, analysis Analysis, opts SimulateOptions) SimulationResult {
+	txCost := opts.TxCost
+	if len(opts.TxMix) > 0 {
+		txCost = BlendedTxCost(opts.TxMix)
+	}
+	targetTx := opts.TargetTx
+
+	sim := SimulationResult{
+		TargetTx:           targetTx,
+		TxCost:             txCost,
+		CurrentAvailable:   snapshot.EnergyAvailable,
+		RecoveryRatePerSec: analysis.EnergyRegenRatePerSec,
+		HourlyProjection:   make([]int64, 24),
+		Prices:             opts.Prices,
+	}
+
+	if opts.BandwidthCost > 0 {
+		sim.Bandwidth = simulateBandwidth(snapshot, analysis, opts.BandwidthCost, targetTx)
+	}
+
+	if txCost > 0 {
+		// Immediate capacity
+		sim.ImmediateCapacity = snapshot.EnergyAvailable / txCost
+
+		// Seconds per transaction (recovery time)
+		if analysis.EnergyRegenRatePerSec > 0 {
+			sim.SecondsPerTx = float64(txCost) / analysis.EnergyRegenRatePerSec
+		}
+
+		// 24h capacity = immediate + recovered
+		recoveredEnergy := int64(analysis.EnergyRegenRatePerDay)
+		sim.Total24hCapacity = sim.ImmediateCapacity + (recoveredEnergy / txCost)
+
+		sim.HourlyProjection = projectHourly(snapshot.EnergyAvailable, snapshot.EnergyLimit, analysis.EnergyRegenRatePerDay, txCost)
+
+		// Regeneration scales with the limit, so losing the delegated-in
+		// part takes its share of the buffer and of the daily recovery
+		if opts.DelegatedInEnergy > 0 && snapshot.EnergyLimit > 0 {
+			ownShare := float64(snapshot.EnergyLimit-min(opts.DelegatedInEnergy, snapshot.EnergyLimit)) / float64(snapshot.EnergyLimit)
+			sim.DelegatedInEnergy = opts.DelegatedInEnergy
+			sim.OwnStake24hCapacity = int64(float64(snapshot.EnergyAvailable)*ownShare)/txCost +
+				int64(analysis.EnergyRegenRatePerDay*ownShare)/txCost
+		}
+	}
+
+	// The binding constraint is the resource that allows fewer transactions
+	switch {
+	case txCost > 0 && (sim.Bandwidth == nil || sim.Total24hCapacity <= sim.Bandwidth.Total24hCapacity):
+		sim.BindingConstraint = ConstraintEnergy
+		sim.EffectiveCapacity = sim.Total24hCapacity
+	case sim.Bandwidth != nil:
+		sim.BindingConstraint = ConstraintBandwidth
+		sim.EffectiveCapacity = sim.Bandwidth.Total24hCapacity
+	}
+
+	// Can reach target?
+	sim.CanReachTarget = sim.EffectiveCapacity >= int64(targetTx)
+
+	// Required energy limit for target
+	if txCost > 0 && sim.Total24hCapacity < int64(targetTx) {
+		// Need: targetTx * txCost per day
+		// Recovery rate = limit / 86400
+		// So: limit = targetTx * txCost
+		sim.RequiredEnergyLimit = int64(targetTx) * txCost
+	}
+
+	// Whatever the resources can't cover over 24h is paid by burning TRX
+	sim.EnergyShortfall = shortfall(int64(targetTx)*txCost, snapshot.EnergyAvailable, analysis.EnergyRegenRatePerDay)
+	if sim.Bandwidth != nil {
+		regenPerDay := analysis.StakedBandwidthRegenRatePerDay + float64(snapshot.FreeNetLimit)
+		sim.BandwidthShortfall = shortfall(int64(targetTx)*opts.BandwidthCost, snapshot.BandwidthAvailable, regenPerDay)
+	}
+	sim.TrxBurnedEstimate = opts.Prices.BurnTRX(sim.EnergyShortfall, sim.BandwidthShortfall)
+
+	sim.TxMix = simulateMix(opts.TxMix, sim, snapshot, analysis)
+
+	return sim
+}
+
+// BlendedTxCost returns the weighted average energy cost of a transaction mix,
+// rounded to whole energy. Weights don't need to sum to 1.
+func BlendedTxCost(mix []TxCostShare) int64 {
+	var cost, weight float64
+	for _, share := range mix {
+		cost += float64(share.Cost) * share.Weight
+		weight += share.Weight
+	}
+	if weight <= 0 {
+		return 0
+	}
+	return int64(math.Round(cost / weight))
+}
+
+// simulateMix splits the effective capacity of a simulation over the
+// transaction types of a mix in proportion to their weights
+func simulateMix(mix []TxCostShare, sim SimulationResult, snapshot Snapshot, analysis Analysis) []TxMixResult {
+	var total float64
+	for _, share := range mix {
+		total += share.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	results := make([]TxMixResult, len(mix))
+	for i, share := range mix {
+		results[i] = TxMixResult{
+			Cost:             share.Cost,
+			Weight:           share.Weight / total,
+			Total24hCapacity: int64(float64(sim.EffectiveCapacity) * share.Weight / total),
+		}
+		if share.Cost > 0 {
+			results[i].AloneCapacity = snapshot.EnergyAvailable/share.Cost + int64(analysis.EnergyRegenRatePerDay)/share.Cost
+		}
+	}
+	return results
+}
+
+// shortfall returns how much of needed is not covered by available plus one day of regeneration
+func shortfall(needed, available int64, regenPerDay float64) int64 {
+	return max(needed-available-int64(regenPerDay), 0)
+}
+
+// simulateBandwidth mirrors the energy simulation for bandwidth-bound transactions.
+//
+// Staked and free bandwidth are separate pools: a transaction is paid in full
+// from staked bandwidth when enough is left and only otherwise from the free
+// daily allowance. A transaction never splits its cost across the two pools.
+func simulateBandwidth(snapshot Snapshot, analysis Analysis, bwCost int64, targetTx int) *BandwidthSimulation {
+	sim := &BandwidthSimulation{
+		TxCost:              bwCost,
+		CurrentAvailable:    snapshot.BandwidthAvailable,
+		StakedAvailable:     snapshot.StakedBandwidthAvailable,
+		FreeAvailable:       snapshot.FreeBandwidthAvailable,
+		ImmediateFromStaked: snapshot.StakedBandwidthAvailable / bwCost,
+		ImmediateFromFree:   snapshot.FreeBandwidthAvailable / bwCost,
+		RecoveryRatePerSec:  analysis.BandwidthRegenRatePerSec,
+	}
+	sim.ImmediateCapacity = sim.ImmediateFromStaked + sim.ImmediateFromFree
+
+	// The free allowance fully recovers within 24h; its regen is too slow
+	// to measure in a short session, so the daily limit is used instead
+	stakedPerDay := analysis.StakedBandwidthRegenRatePerDay
+	freePerDay := float64(snapshot.FreeNetLimit)
+
+	if analysis.BandwidthRegenRatePerSec > 0 {
+		sim.SecondsPerTx = float64(bwCost) / analysis.BandwidthRegenRatePerSec
+	}
+
+	sim.SustainedFromStaked = stakedPerDay / float64(bwCost)
+	sim.SustainedFromFree = float64(int64(freePerDay) / bwCost)
+	sim.SustainedTxPerDay = sim.SustainedFromStaked + sim.SustainedFromFree
+
+	sim.Total24hCapacity = sim.ImmediateCapacity + int64(stakedPerDay)/bwCost + int64(freePerDay)/bwCost
+	sim.CanReachTarget = sim.Total24hCapacity >= int64(targetTx)
+
+	sim.HourlyProjection = make([]int64, 24)
+	staked, free := snapshot.StakedBandwidthAvailable, snapshot.FreeBandwidthAvailable
+	for hour := 0; hour < 24; hour++ {
+		// Staked bandwidth is consumed first, then the free allowance
+		fromStaked := staked / bwCost
+		fromFree := free / bwCost
+		sim.HourlyProjection[hour] = fromStaked + fromFree
+
+		staked = min(staked-fromStaked*bwCost+int64(stakedPerDay/24), snapshot.NetLimit)
+		free = min(free-fromFree*bwCost+int64(freePerDay/24), snapshot.FreeNetLimit)
+	}
+
+	if !sim.CanReachTarget {
+		sim.RequiredBandwidthLimit = int64(targetTx) * bwCost
+	}
+
+	return sim
+}
+
+// projectHourly spends all available resource every hour and recovers at the
+// given daily rate, capped at the limit. Returns transactions per hour.
+func projectHourly(available, limit int64, regenPerDay float64, cost int64) []int64 {
+	projection := make([]int64, 24)
+	perHour := int64(regenPerDay / 24)
+	current := available
+
+	for hour := 0; hour < 24; hour++ {
+		txThisHour := current / cost
+		projection[hour] = txThisHour
+
+		// Use resource and recover
+		used := txThisHour * cost
+		current = current - used + perHour
+		if current > limit {
+			current = limit
+		}
+	}
+
+	return projection
+}
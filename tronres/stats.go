@@ -0,0 +1,82 @@
+package tronres
+
+import (
+	"math"
+	"slices"
+)
+
+// runningStats accumulates min, max, mean and variance in one pass
+// using Welford's algorithm
+type runningStats struct {
+	n        int
+	min, max int64
+	mean, m2 float64
+}
+
+func (s *runningStats) add(v int64) {
+	if s.n == 0 || v < s.min {
+		s.min = v
+	}
+	if s.n == 0 || v > s.max {
+		s.max = v
+	}
+
+	s.n++
+	d := float64(v) - s.mean
+	s.mean += d / float64(s.n)
+	s.m2 += d * (float64(v) - s.mean)
+}
+
+// result returns the population statistics, all zero for no values
+func (s *runningStats) result() ResourceStats {
+	if s.n == 0 {
+		return ResourceStats{}
+	}
+	return ResourceStats{
+		Min:    s.min,
+		Max:    s.max,
+		Mean:   s.mean,
+		Stddev: math.Sqrt(s.m2 / float64(s.n)),
+	}
+}
+
+// spread returns the population standard deviation of values and the
+// coefficient of variation, stddev / mean, which is 0 when the mean isn't positive
+func spread(values []float64) (stddev, cv float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		d := v - mean
+		stddev += d * d
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+
+	if mean > 0 {
+		cv = stddev / mean
+	}
+	return stddev, cv
+}
+
+// percentile returns the p-th percentile (0-100) of values, interpolating
+// linearly between the closest ranks. values is not modified.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,61 @@
+package tronres
+
+import "time"
+
+// rateWindows computes the energy and bandwidth regeneration rates over
+// successive windows starting at the first snapshot. Deltas excluded from the
+// analysis (limit changes, resume gaps, outliers) are left out of both the
+// regenerated amount and the time it is divided by.
+func rateWindows(snapshots []Snapshot, window time.Duration) []RateWindow {
+	windowMs := window.Milliseconds()
+	if len(snapshots) < 2 || windowMs <= 0 {
+		return nil
+	}
+
+	first := snapshots[0]
+	last := snapshots[len(snapshots)-1]
+	if last.ElapsedMs <= first.ElapsedMs {
+		return nil
+	}
+	count := int((last.ElapsedMs-first.ElapsedMs-1)/windowMs) + 1
+
+	type totals struct {
+		samples              int
+		countedMs            int64
+		energyRegenerated    int64
+		bandwidthRegenerated int64
+	}
+	acc := make([]totals, count)
+
+	for i := 1; i < len(snapshots); i++ {
+		prev, s := snapshots[i-1], snapshots[i]
+		// An interval ending exactly on a boundary belongs to the window before it
+		k := max(min(int((s.ElapsedMs-first.ElapsedMs-1)/windowMs), count-1), 0)
+		acc[k].samples++
+		if excludedDelta(prev, s) {
+			continue
+		}
+		acc[k].countedMs += s.ElapsedMs - prev.ElapsedMs
+		acc[k].energyRegenerated += max(s.DeltaEnergy, 0)
+		acc[k].bandwidthRegenerated += max(s.DeltaBandwidth, 0)
+	}
+
+	windows := make([]RateWindow, count)
+	for k, t := range acc {
+		startMs := int64(k) * windowMs
+		endMs := min(startMs+windowMs, last.ElapsedMs-first.ElapsedMs)
+		w := RateWindow{
+			WindowStart: first.Timestamp.Add(time.Duration(startMs) * time.Millisecond),
+			WindowEnd:   first.Timestamp.Add(time.Duration(endMs) * time.Millisecond),
+			Samples:     t.samples,
+		}
+		if t.countedMs > 0 {
+			sec := float64(t.countedMs) / 1000.0
+			w.EnergyRegenRate = float64(t.energyRegenerated) / sec
+			w.BandwidthRegenRate = float64(t.bandwidthRegenerated) / sec
+		}
+		windows[k] = w
+	}
+
+	return windows
+}